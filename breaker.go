@@ -0,0 +1,208 @@
+package pam4sdk
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending request when circuit breaker is open
+var ErrCircuitOpen = errors.New("pam circuit breaker is open")
+
+// CircuitState is state of circuit breaker
+type CircuitState int
+
+// Circuit breaker states
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+// String return name of state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerConfig is configuration of circuit breaker, zero fields use default value
+type CircuitBreakerConfig struct {
+	// Name is reported to OnStateChange, usually the connector name
+	Name string
+	// FailureRatio open the circuit when failures / requests in window reach it, default 0.5
+	FailureRatio float64
+	// MinRequests is number of requests in window before ratio is evaluated, default 10
+	MinRequests int
+	// Window is period that requests are counted in closed state, default 1 minute
+	Window time.Duration
+	// OpenTimeout is time circuit stay open before allowing trial requests, default 30 seconds
+	OpenTimeout time.Duration
+	// HalfOpenMaxRequests is number of trial requests that must succeed to close circuit, default 1
+	HalfOpenMaxRequests int
+	// OnStateChange is called after state changed
+	OnStateChange func(name string, from CircuitState, to CircuitState)
+}
+
+// CircuitBreaker fail fast with ErrCircuitOpen when PAM keep failing, it is safe for concurrent use.
+// Network errors and 5xx responses are failures, other responses are successes
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	config           CircuitBreakerConfig
+	state            CircuitState
+	windowStart      time.Time
+	requests         int
+	failures         int
+	openedAt         time.Time
+	halfOpenInFlight int
+	halfOpenSuccess  int
+	changes          []circuitChange
+	now              func() time.Time
+}
+
+type circuitChange struct {
+	from CircuitState
+	to   CircuitState
+}
+
+// NewCircuitBreaker return closed circuit breaker
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	if config.FailureRatio <= 0 || config.FailureRatio > 1 {
+		config.FailureRatio = 0.5
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = 10
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenMaxRequests <= 0 {
+		config.HalfOpenMaxRequests = 1
+	}
+	b := &CircuitBreaker{
+		config: config,
+		state:  CircuitClosed,
+		now:    time.Now,
+	}
+	b.windowStart = b.now()
+	return b
+}
+
+// State return current state
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.unlock()
+	b.expire(b.now())
+	return b.state
+}
+
+// allow return ErrCircuitOpen when request must not be sent,
+// a nil result must be followed by either record or release
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.unlock()
+	b.expire(b.now())
+
+	switch b.state {
+	case CircuitOpen:
+		return ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.halfOpenInFlight+b.halfOpenSuccess >= b.config.HalfOpenMaxRequests {
+			return ErrCircuitOpen
+		}
+		b.halfOpenInFlight++
+	}
+	return nil
+}
+
+// release give back permission from allow when request was not sent
+func (b *CircuitBreaker) release() {
+	b.mu.Lock()
+	defer b.unlock()
+	if b.state == CircuitHalfOpen && b.halfOpenInFlight > 0 {
+		b.halfOpenInFlight--
+	}
+}
+
+// record count result of request allowed by allow
+func (b *CircuitBreaker) record(success bool) {
+	b.mu.Lock()
+	defer b.unlock()
+	now := b.now()
+
+	switch b.state {
+	case CircuitHalfOpen:
+		if b.halfOpenInFlight > 0 {
+			b.halfOpenInFlight--
+		}
+		if !success {
+			b.setState(CircuitOpen, now)
+			return
+		}
+		b.halfOpenSuccess++
+		if b.halfOpenSuccess >= b.config.HalfOpenMaxRequests {
+			b.setState(CircuitClosed, now)
+		}
+	case CircuitClosed:
+		if now.Sub(b.windowStart) >= b.config.Window {
+			b.resetWindow(now)
+		}
+		b.requests++
+		if !success {
+			b.failures++
+		}
+		if b.requests >= b.config.MinRequests &&
+			float64(b.failures)/float64(b.requests) >= b.config.FailureRatio {
+			b.setState(CircuitOpen, now)
+		}
+	}
+}
+
+// expire move open circuit to half-open after OpenTimeout
+func (b *CircuitBreaker) expire(now time.Time) {
+	if b.state == CircuitOpen && now.Sub(b.openedAt) >= b.config.OpenTimeout {
+		b.setState(CircuitHalfOpen, now)
+	}
+}
+
+func (b *CircuitBreaker) setState(state CircuitState, now time.Time) {
+	from := b.state
+	b.state = state
+	b.halfOpenInFlight = 0
+	b.halfOpenSuccess = 0
+	if state == CircuitOpen {
+		b.openedAt = now
+	}
+	b.resetWindow(now)
+	if from != state {
+		b.changes = append(b.changes, circuitChange{from: from, to: state})
+	}
+}
+
+// unlock release the lock then notify state changes, so callback can use the breaker
+func (b *CircuitBreaker) unlock() {
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+	if b.config.OnStateChange == nil {
+		return
+	}
+	for _, c := range changes {
+		b.config.OnStateChange(b.config.Name, c.from, c.to)
+	}
+}
+
+func (b *CircuitBreaker) resetWindow(now time.Time) {
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+}
@@ -0,0 +1,121 @@
+package pam4sdk
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type CircuitBreakerTestSuite struct {
+	suite.Suite
+}
+
+func TestCircuitBreakerTestSuite(t *testing.T) {
+	suite.Run(t, new(CircuitBreakerTestSuite))
+}
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func (ts *CircuitBreakerTestSuite) newBreaker(clock *fakeClock, changes *[]string) *CircuitBreaker {
+	b := NewCircuitBreaker(CircuitBreakerConfig{
+		Name:         ConnectorConnect,
+		FailureRatio: 0.5,
+		MinRequests:  4,
+		OpenTimeout:  10 * time.Second,
+		OnStateChange: func(name string, from CircuitState, to CircuitState) {
+			*changes = append(*changes, name+":"+from.String()+"->"+to.String())
+		},
+	})
+	b.now = clock.now
+	return b
+}
+
+func (ts *CircuitBreakerTestSuite) TestRecord_GivenFailureRatioReached_ExpectOpen() {
+	is := assert.New(ts.T())
+	clock := &fakeClock{t: time.Now()}
+	changes := []string{}
+	b := ts.newBreaker(clock, &changes)
+
+	for _, success := range []bool{true, false, true} {
+		is.NoError(b.allow())
+		b.record(success)
+	}
+	is.Equal(CircuitClosed, b.State())
+
+	is.NoError(b.allow())
+	b.record(false)
+	is.Equal(CircuitOpen, b.State())
+	is.Equal(ErrCircuitOpen, b.allow())
+	is.Equal([]string{"connect:closed->open"}, changes)
+}
+
+func (ts *CircuitBreakerTestSuite) TestAllow_GivenOpenTimeoutPassed_ExpectHalfOpenThenClosed() {
+	is := assert.New(ts.T())
+	clock := &fakeClock{t: time.Now()}
+	changes := []string{}
+	b := ts.newBreaker(clock, &changes)
+	for i := 0; i < 4; i++ {
+		b.allow()
+		b.record(false)
+	}
+
+	clock.t = clock.t.Add(10 * time.Second)
+	is.Equal(CircuitHalfOpen, b.State())
+	is.NoError(b.allow())
+	// Only one trial request is allowed at a time
+	is.Equal(ErrCircuitOpen, b.allow())
+
+	b.record(true)
+	is.Equal(CircuitClosed, b.State())
+	is.Equal([]string{"connect:closed->open", "connect:open->half-open", "connect:half-open->closed"}, changes)
+}
+
+func (ts *CircuitBreakerTestSuite) TestRecord_GivenHalfOpenFailure_ExpectOpenAgain() {
+	is := assert.New(ts.T())
+	clock := &fakeClock{t: time.Now()}
+	changes := []string{}
+	b := ts.newBreaker(clock, &changes)
+	for i := 0; i < 4; i++ {
+		b.allow()
+		b.record(false)
+	}
+
+	clock.t = clock.t.Add(10 * time.Second)
+	is.NoError(b.allow())
+	b.record(false)
+	is.Equal(CircuitOpen, b.State())
+}
+
+func (ts *CircuitBreakerTestSuite) TestRequester_GivenPAMDown_ExpectFailFastWithErrCircuitOpen() {
+	is := assert.New(ts.T())
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{MinRequests: 2})
+	cfg := NewCustomRequesterConfig(server.URL, "x-app-id", "x-secret", "app-id", "secret", time.Second)
+	rqt := NewRequester(cfg, NewLoggerSimple(), RequesterCircuitBreaker(breaker))
+
+	for i := 0; i < 2; i++ {
+		_, err := rqt.Get("/abc", nil)
+		is.True(IsRetryable(err))
+	}
+	_, err := rqt.Get("/abc", nil)
+	is.True(errors.Is(err, ErrCircuitOpen))
+	is.Equal(int32(2), atomic.LoadInt32(&calls))
+}
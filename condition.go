@@ -0,0 +1,687 @@
+package pam4sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Condition kinds written in "type" of serialized condition
+const (
+	ConditionAttribute = "attribute"
+	ConditionTag       = "tag"
+	ConditionEvent     = "event"
+	ConditionCampaign  = "campaign"
+	ConditionSegment   = "segment"
+	ConditionNot       = "not"
+	ConditionGroup     = "group"
+)
+
+// TriggerTypeAnd is type of triggers written by SegmentTriggers, such trigger match contacts
+// who satisfy every condition of it
+const TriggerTypeAnd = "and"
+
+// Operator compare attribute value or event count
+type Operator string
+
+// Operators
+const (
+	OpEqual          Operator = "eq"
+	OpNotEqual       Operator = "ne"
+	OpGreater        Operator = "gt"
+	OpGreaterOrEqual Operator = "gte"
+	OpLess           Operator = "lt"
+	OpLessOrEqual    Operator = "lte"
+	OpContains       Operator = "contains"
+	OpNotContains    Operator = "not_contains"
+	OpIn             Operator = "in"
+	OpExists         Operator = "exists"
+	OpNotExists      Operator = "not_exists"
+)
+
+// valueless return true when operator does not compare with a value
+func (op Operator) valueless() bool {
+	return op == OpExists || op == OpNotExists
+}
+
+func (op Operator) valid() bool {
+	switch op {
+	case OpEqual, OpNotEqual, OpGreater, OpGreaterOrEqual, OpLess, OpLessOrEqual,
+		OpContains, OpNotContains, OpIn, OpExists, OpNotExists:
+		return true
+	}
+	return false
+}
+
+// Logic join conditions of a group
+type Logic string
+
+// Logics
+const (
+	LogicAnd Logic = "and"
+	LogicOr  Logic = "or"
+)
+
+// TimeUnit is unit of event window, same units as Segment.DelayUnit
+type TimeUnit string
+
+// Time units
+const (
+	UnitMinute TimeUnit = "minute"
+	UnitHour   TimeUnit = "hour"
+	UnitDay    TimeUnit = "day"
+	UnitWeek   TimeUnit = "week"
+	UnitMonth  TimeUnit = "month"
+)
+
+// Interaction is what contact did with campaign
+type Interaction string
+
+// Campaign interactions
+const (
+	InteractionReceived  Interaction = "received"
+	InteractionOpened    Interaction = "opened"
+	InteractionClicked   Interaction = "clicked"
+	InteractionConverted Interaction = "converted"
+)
+
+// Condition is typed segment condition, it is built with Attr, HasTag, EventCount, CampaignInteraction,
+// InSegment, Not, And and Or. Conditions parsed from PAM which the SDK does not know are RawCondition
+type Condition interface {
+	// Validate return error when condition cannot be serialized
+	Validate() error
+	toMap() map[string]interface{}
+}
+
+// AttributeCondition compare contact attribute with value
+type AttributeCondition struct {
+	Attribute string
+	Operator  Operator
+	// Value is ignored by OpExists and OpNotExists, OpIn expect a slice
+	Value interface{}
+}
+
+// Attr return condition comparing attribute with value
+func Attr(attribute string, op Operator, value interface{}) *AttributeCondition {
+	return &AttributeCondition{Attribute: attribute, Operator: op, Value: value}
+}
+
+// Validate implement Condition
+func (c *AttributeCondition) Validate() error {
+	if len(c.Attribute) == 0 {
+		return conditionError(ConditionAttribute, "attribute is required")
+	}
+	if !c.Operator.valid() {
+		return conditionError(ConditionAttribute, fmt.Sprintf("operator %q is unknown", c.Operator))
+	}
+	if c.Value == nil && !c.Operator.valueless() {
+		return conditionError(ConditionAttribute, fmt.Sprintf("operator %s of %s require a value", c.Operator, c.Attribute))
+	}
+	return nil
+}
+
+func (c *AttributeCondition) toMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"type":      ConditionAttribute,
+		"attribute": c.Attribute,
+		"operator":  string(c.Operator),
+	}
+	if !c.Operator.valueless() {
+		m["value"] = c.Value
+	}
+	return m
+}
+
+// TagCondition match contacts having or lacking tag
+type TagCondition struct {
+	Tag string
+	Has bool
+}
+
+// HasTag return condition matching contacts having tag
+func HasTag(tag string) *TagCondition {
+	return &TagCondition{Tag: tag, Has: true}
+}
+
+// LacksTag return condition matching contacts without tag
+func LacksTag(tag string) *TagCondition {
+	return &TagCondition{Tag: tag}
+}
+
+// Validate implement Condition
+func (c *TagCondition) Validate() error {
+	if len(c.Tag) == 0 {
+		return conditionError(ConditionTag, "tag is required")
+	}
+	return nil
+}
+
+func (c *TagCondition) toMap() map[string]interface{} {
+	op := "has"
+	if !c.Has {
+		op = "not_has"
+	}
+	return map[string]interface{}{
+		"type":     ConditionTag,
+		"tag":      c.Tag,
+		"operator": op,
+	}
+}
+
+// EventCondition match contacts whose number of event within window satisfy Operator and Count
+type EventCondition struct {
+	Event    string
+	Operator Operator
+	Count    int
+	// WithinAmount and WithinUnit limit events to the last window, zero amount count every event
+	WithinAmount int
+	WithinUnit   TimeUnit
+}
+
+// EventCount return condition matching contacts who sent event at least count times
+func EventCount(event string, count int) *EventCondition {
+	return &EventCondition{Event: event, Operator: OpGreaterOrEqual, Count: count}
+}
+
+// Within limit counted events to the last amount of unit
+func (c *EventCondition) Within(amount int, unit TimeUnit) *EventCondition {
+	c.WithinAmount = amount
+	c.WithinUnit = unit
+	return c
+}
+
+// Validate implement Condition
+func (c *EventCondition) Validate() error {
+	if len(c.Event) == 0 {
+		return conditionError(ConditionEvent, "event is required")
+	}
+	switch c.Operator {
+	case OpEqual, OpNotEqual, OpGreater, OpGreaterOrEqual, OpLess, OpLessOrEqual:
+	default:
+		return conditionError(ConditionEvent, fmt.Sprintf("operator %q cannot compare count", c.Operator))
+	}
+	if c.Count < 0 {
+		return conditionError(ConditionEvent, "count must not be negative")
+	}
+	if c.WithinAmount < 0 {
+		return conditionError(ConditionEvent, "window must not be negative")
+	}
+	if c.WithinAmount > 0 {
+		switch c.WithinUnit {
+		case UnitMinute, UnitHour, UnitDay, UnitWeek, UnitMonth:
+		default:
+			return conditionError(ConditionEvent, fmt.Sprintf("window unit %q is unknown", c.WithinUnit))
+		}
+	}
+	return nil
+}
+
+func (c *EventCondition) toMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"type":     ConditionEvent,
+		"event":    c.Event,
+		"operator": string(c.Operator),
+		"count":    c.Count,
+	}
+	if c.WithinAmount > 0 {
+		// Amount is string like Segment.DelayAmount
+		m["within_amount"] = strconv.Itoa(c.WithinAmount)
+		m["within_unit"] = string(c.WithinUnit)
+	}
+	return m
+}
+
+// CampaignCondition match contacts who did or did not interact with campaign
+type CampaignCondition struct {
+	CampaignID  string
+	Interaction Interaction
+	Did         bool
+}
+
+// CampaignInteraction return condition matching contacts who interacted with campaign
+func CampaignInteraction(campaignID string, interaction Interaction) *CampaignCondition {
+	return &CampaignCondition{CampaignID: campaignID, Interaction: interaction, Did: true}
+}
+
+// Not negate condition, it match contacts who did not interact with campaign
+func (c *CampaignCondition) Not() *CampaignCondition {
+	c.Did = !c.Did
+	return c
+}
+
+// Validate implement Condition
+func (c *CampaignCondition) Validate() error {
+	if len(c.CampaignID) == 0 {
+		return conditionError(ConditionCampaign, "campaign id is required")
+	}
+	switch c.Interaction {
+	case InteractionReceived, InteractionOpened, InteractionClicked, InteractionConverted:
+	default:
+		return conditionError(ConditionCampaign, fmt.Sprintf("interaction %q is unknown", c.Interaction))
+	}
+	return nil
+}
+
+func (c *CampaignCondition) toMap() map[string]interface{} {
+	op := "did"
+	if !c.Did {
+		op = "did_not"
+	}
+	return map[string]interface{}{
+		"type":        ConditionCampaign,
+		"campaign_id": c.CampaignID,
+		"interaction": string(c.Interaction),
+		"operator":    op,
+	}
+}
+
+// SegmentCondition match contacts who are or are not member of another segment
+type SegmentCondition struct {
+	// Segment is id or alias of the segment
+	Segment string
+	In      bool
+}
+
+// InSegment return condition matching members of segment
+func InSegment(segment string) *SegmentCondition {
+	return &SegmentCondition{Segment: segment, In: true}
+}
+
+// Validate implement Condition
+func (c *SegmentCondition) Validate() error {
+	if len(c.Segment) == 0 {
+		return conditionError(ConditionSegment, "segment is required")
+	}
+	return nil
+}
+
+func (c *SegmentCondition) toMap() map[string]interface{} {
+	op := "in"
+	if !c.In {
+		op = "not_in"
+	}
+	return map[string]interface{}{
+		"type":     ConditionSegment,
+		"segment":  c.Segment,
+		"operator": op,
+	}
+}
+
+// NotCondition match contacts who do not satisfy Condition
+type NotCondition struct {
+	Condition Condition
+}
+
+// Not return negation of condition, tag, campaign and segment conditions are negated in place
+// and double negation is removed
+func Not(condition Condition) Condition {
+	switch c := condition.(type) {
+	case *TagCondition:
+		return &TagCondition{Tag: c.Tag, Has: !c.Has}
+	case *CampaignCondition:
+		return &CampaignCondition{CampaignID: c.CampaignID, Interaction: c.Interaction, Did: !c.Did}
+	case *SegmentCondition:
+		return &SegmentCondition{Segment: c.Segment, In: !c.In}
+	case *NotCondition:
+		return c.Condition
+	}
+	return &NotCondition{Condition: condition}
+}
+
+// Validate implement Condition
+func (c *NotCondition) Validate() error {
+	if c.Condition == nil {
+		return conditionError(ConditionNot, "condition is required")
+	}
+	return c.Condition.Validate()
+}
+
+func (c *NotCondition) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"type":      ConditionNot,
+		"condition": c.Condition.toMap(),
+	}
+}
+
+// GroupCondition join conditions with Logic
+type GroupCondition struct {
+	Logic      Logic
+	Conditions []Condition
+}
+
+// And return group matching contacts who satisfy every condition
+func And(conditions ...Condition) *GroupCondition {
+	return &GroupCondition{Logic: LogicAnd, Conditions: conditions}
+}
+
+// Or return group matching contacts who satisfy any condition
+func Or(conditions ...Condition) *GroupCondition {
+	return &GroupCondition{Logic: LogicOr, Conditions: conditions}
+}
+
+// Validate implement Condition
+func (c *GroupCondition) Validate() error {
+	if c.Logic != LogicAnd && c.Logic != LogicOr {
+		return conditionError(ConditionGroup, fmt.Sprintf("logic %q is unknown", c.Logic))
+	}
+	if len(c.Conditions) == 0 {
+		return conditionError(ConditionGroup, "group must not be empty")
+	}
+	for _, child := range c.Conditions {
+		if child == nil {
+			return conditionError(ConditionGroup, "group contain nil condition")
+		}
+		if err := child.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *GroupCondition) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"type":       ConditionGroup,
+		"logic":      string(c.Logic),
+		"conditions": conditionMaps(c.Conditions),
+	}
+}
+
+// TriggerCondition is trigger from PAM whose type is not TriggerTypeAnd, its conditions are parsed
+// and it is written back with the same type
+type TriggerCondition struct {
+	Type       string
+	Conditions []Condition
+}
+
+// Validate implement Condition
+func (c *TriggerCondition) Validate() error {
+	if len(c.Conditions) == 0 {
+		return conditionError(fmt.Sprintf("%q trigger", c.Type), "trigger must not be empty")
+	}
+	for _, child := range c.Conditions {
+		if child == nil {
+			return conditionError(fmt.Sprintf("%q trigger", c.Type), "trigger contain nil condition")
+		}
+		if err := child.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *TriggerCondition) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"type":       c.Type,
+		"conditions": conditionMaps(c.Conditions),
+	}
+}
+
+// RawCondition is serialized condition kept as is, ParseCondition return it for unknown type
+// or unsupported field so segments from PAM are still parsed and written back unchanged
+type RawCondition struct {
+	Raw map[string]interface{}
+}
+
+// Type return "type" of serialized condition
+func (c *RawCondition) Type() string {
+	return stringField(c.Raw, "type")
+}
+
+// Validate implement Condition
+func (c *RawCondition) Validate() error {
+	if len(c.Raw) == 0 {
+		return conditionError("raw", "condition is empty")
+	}
+	return nil
+}
+
+func (c *RawCondition) toMap() map[string]interface{} {
+	return c.Raw
+}
+
+func conditionMaps(conditions []Condition) []interface{} {
+	maps := make([]interface{}, 0, len(conditions))
+	for _, c := range conditions {
+		maps = append(maps, c.toMap())
+	}
+	return maps
+}
+
+func conditionError(kind string, reason string) error {
+	return NewErrM(fmt.Sprintf("pam %s condition: %s", kind, reason))
+}
+
+// SegmentTriggers serialize condition into Segment.Triggers. Triggers are joined with OR and
+// conditions of a trigger are joined with AND, so top level Or become one trigger per branch
+// and deeper groups are kept as group conditions. TriggerCondition is written as trigger of its type
+func SegmentTriggers(condition Condition) ([]*SegmentTrigger, error) {
+	if condition == nil {
+		return nil, conditionError(ConditionGroup, "condition is required")
+	}
+	if err := condition.Validate(); err != nil {
+		return nil, err
+	}
+	branches := []Condition{condition}
+	if g, ok := condition.(*GroupCondition); ok && g.Logic == LogicOr {
+		branches = g.Conditions
+	}
+	triggers := make([]*SegmentTrigger, 0, len(branches))
+	for _, branch := range branches {
+		if t, ok := branch.(*TriggerCondition); ok {
+			triggers = append(triggers, &SegmentTrigger{Type: t.Type, Conditions: conditionMaps(t.Conditions)})
+			continue
+		}
+		conditions := []Condition{branch}
+		if g, ok := branch.(*GroupCondition); ok && g.Logic == LogicAnd {
+			conditions = g.Conditions
+		}
+		triggers = append(triggers, &SegmentTrigger{Type: TriggerTypeAnd, Conditions: conditionMaps(conditions)})
+	}
+	return triggers, nil
+}
+
+// ParseSegmentTriggers parse Segment.Triggers back into typed condition, a single condition is
+// returned as is, trigger of TriggerTypeAnd as And group and trigger of other type as TriggerCondition
+func ParseSegmentTriggers(triggers []*SegmentTrigger) (Condition, error) {
+	return parseSegmentTriggers(triggers, true)
+}
+
+// parseSegmentTriggers parse triggers, conditions which would not be serialized back to the same
+// JSON are kept as RawCondition only when strict
+func parseSegmentTriggers(triggers []*SegmentTrigger, strict bool) (Condition, error) {
+	if len(triggers) == 0 {
+		return nil, conditionError(ConditionGroup, "segment has no trigger")
+	}
+	branches := make([]Condition, 0, len(triggers))
+	for _, t := range triggers {
+		if t == nil {
+			return nil, conditionError(ConditionGroup, "trigger is nil")
+		}
+		conditions, err := parseConditions(t.Conditions, strict)
+		if err != nil {
+			return nil, err
+		}
+		if t.Type != TriggerTypeAnd || len(conditions) == 0 {
+			branches = append(branches, &TriggerCondition{Type: t.Type, Conditions: conditions})
+			continue
+		}
+		branches = append(branches, simplify(And(conditions...)))
+	}
+	if len(branches) == 1 {
+		return branches[0], nil
+	}
+	return Or(branches...), nil
+}
+
+// CampaignTriggers serialize condition into Triggers.Triggers of UpdateCampaignTrigger
+func CampaignTriggers(condition Condition) ([]interface{}, error) {
+	triggers, err := SegmentTriggers(condition)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, 0, len(triggers))
+	for _, t := range triggers {
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// ParseCampaignTriggers parse Triggers.Triggers back into typed condition
+func ParseCampaignTriggers(triggers []interface{}) (Condition, error) {
+	js, err := json.Marshal(triggers)
+	if err != nil {
+		return nil, NewErr(err)
+	}
+	var segmentTriggers []*SegmentTrigger
+	if err := json.Unmarshal(js, &segmentTriggers); err != nil {
+		return nil, NewErr(err)
+	}
+	return ParseSegmentTriggers(segmentTriggers)
+}
+
+// SetCondition replace triggers of segment with condition
+func (s *Segment) SetCondition(condition Condition) error {
+	triggers, err := SegmentTriggers(condition)
+	if err != nil {
+		return err
+	}
+	s.Triggers = triggers
+	return nil
+}
+
+// Condition parse triggers of segment into typed condition
+func (s *Segment) Condition() (Condition, error) {
+	return ParseSegmentTriggers(s.Triggers)
+}
+
+// SetCondition replace triggers of campaign with condition
+func (t *Triggers) SetCondition(condition Condition) error {
+	triggers, err := CampaignTriggers(condition)
+	if err != nil {
+		return err
+	}
+	t.Triggers = triggers
+	return nil
+}
+
+// Condition parse triggers of campaign into typed condition
+func (t *Triggers) Condition() (Condition, error) {
+	return ParseCampaignTriggers(t.Triggers)
+}
+
+// simplify return the only condition of group
+func simplify(g *GroupCondition) Condition {
+	if len(g.Conditions) == 1 {
+		return g.Conditions[0]
+	}
+	return g
+}
+
+func parseConditions(raw []interface{}, strict bool) ([]Condition, error) {
+	conditions := make([]Condition, 0, len(raw))
+	for _, r := range raw {
+		c, err := parseCondition(r, strict)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, nil
+}
+
+// ParseCondition parse serialized condition, raw is map decoded from JSON or any value marshalling to it.
+// Condition of unknown type, which does not pass Validate or which would not be serialized back
+// to the same JSON, such as one having extra field, is returned as RawCondition
+func ParseCondition(raw interface{}) (Condition, error) {
+	return parseCondition(raw, true)
+}
+
+func parseCondition(raw interface{}, strict bool) (Condition, error) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		js, err := json.Marshal(raw)
+		if err != nil {
+			return nil, NewErr(err)
+		}
+		if err := json.Unmarshal(js, &m); err != nil || m == nil {
+			return nil, conditionError("unknown", "condition is not an object")
+		}
+	}
+
+	kind := stringField(m, "type")
+	var c Condition
+	switch kind {
+	case ConditionAttribute:
+		c = &AttributeCondition{
+			Attribute: stringField(m, "attribute"),
+			Operator:  Operator(stringField(m, "operator")),
+			Value:     m["value"],
+		}
+	case ConditionTag:
+		c = &TagCondition{Tag: stringField(m, "tag"), Has: stringField(m, "operator") != "not_has"}
+	case ConditionEvent:
+		c = &EventCondition{
+			Event:        stringField(m, "event"),
+			Operator:     Operator(stringField(m, "operator")),
+			Count:        intField(m, "count"),
+			WithinAmount: intField(m, "within_amount"),
+			WithinUnit:   TimeUnit(stringField(m, "within_unit")),
+		}
+	case ConditionCampaign:
+		c = &CampaignCondition{
+			CampaignID:  stringField(m, "campaign_id"),
+			Interaction: Interaction(stringField(m, "interaction")),
+			Did:         stringField(m, "operator") != "did_not",
+		}
+	case ConditionSegment:
+		c = &SegmentCondition{Segment: stringField(m, "segment"), In: stringField(m, "operator") != "not_in"}
+	case ConditionNot:
+		inner, err := parseCondition(m["condition"], strict)
+		if err != nil {
+			return nil, err
+		}
+		c = &NotCondition{Condition: inner}
+	case ConditionGroup:
+		children, _ := m["conditions"].([]interface{})
+		conditions, err := parseConditions(children, strict)
+		if err != nil {
+			return nil, err
+		}
+		c = &GroupCondition{Logic: Logic(stringField(m, "logic")), Conditions: conditions}
+	default:
+		return &RawCondition{Raw: m}, nil
+	}
+	if err := c.Validate(); err != nil || (strict && !sameJSON(c.toMap(), m)) {
+		return &RawCondition{Raw: m}, nil
+	}
+	return c, nil
+}
+
+func sameJSON(a interface{}, b interface{}) bool {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	return err == nil && bytes.Equal(ja, jb)
+}
+
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// intField return number field which may be encoded as JSON number or string
+func intField(m map[string]interface{}, key string) int {
+	switch v := m[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	return 0
+}
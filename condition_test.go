@@ -0,0 +1,199 @@
+package pam4sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/mock"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type ConditionTestSuite struct {
+	suite.Suite
+}
+
+func TestConditionTestSuite(t *testing.T) {
+	suite.Run(t, new(ConditionTestSuite))
+}
+
+// segmentFromPAM return response of GET /triggers/{id} having trigger type, condition types,
+// operators and fields which the SDK does not know beside the known ones
+func (ts *ConditionTestSuite) segmentFromPAM() string {
+	data, err := os.ReadFile("testdata/segment_by_id.json")
+	ts.Require().NoError(err)
+	return string(data)
+}
+
+func (ts *ConditionTestSuite) condition() Condition {
+	return Or(
+		And(
+			Attr("age", OpGreaterOrEqual, 18),
+			HasTag("vip"),
+			Or(LacksTag("blocked"), Attr("email", OpExists, nil)),
+		),
+		EventCount("purchase", 3).Within(30, UnitDay),
+		CampaignInteraction("campaign_1", InteractionClicked).Not(),
+	)
+}
+
+func (ts *ConditionTestSuite) TestSetCondition_GivenOrOfBranches_ExpectOneTriggerPerBranch() {
+	is := assert.New(ts.T())
+	segment := &Segment{Name: "Active VIP"}
+
+	is.NoError(segment.SetCondition(ts.condition()))
+
+	js, _ := json.Marshal(segment.Triggers)
+	is.JSONEq(`[
+		{"type":"and","conditions":[
+			{"type":"attribute","attribute":"age","operator":"gte","value":18},
+			{"type":"tag","tag":"vip","operator":"has"},
+			{"type":"group","logic":"or","conditions":[
+				{"type":"tag","tag":"blocked","operator":"not_has"},
+				{"type":"attribute","attribute":"email","operator":"exists"}
+			]}
+		]},
+		{"type":"and","conditions":[
+			{"type":"event","event":"purchase","operator":"gte","count":3,"within_amount":"30","within_unit":"day"}
+		]},
+		{"type":"and","conditions":[
+			{"type":"campaign","campaign_id":"campaign_1","interaction":"clicked","operator":"did_not"}
+		]}
+	]`, string(js))
+}
+
+func (ts *ConditionTestSuite) TestCondition_GivenSegmentFromPAM_ExpectParsedBackToSameTriggers() {
+	is := assert.New(ts.T())
+	segment := &Segment{}
+	is.NoError(segment.SetCondition(ts.condition()))
+	expect, _ := json.Marshal(segment.Triggers)
+
+	fromPAM := &Segment{}
+	is.NoError(json.Unmarshal([]byte(`{"triggers":`+string(expect)+`}`), fromPAM))
+	condition, err := fromPAM.Condition()
+	if is.NoError(err) {
+		group, ok := condition.(*GroupCondition)
+		if is.True(ok) {
+			is.Equal(LogicOr, group.Logic)
+			is.Len(group.Conditions, 3)
+			is.Equal(&EventCondition{Event: "purchase", Operator: OpGreaterOrEqual, Count: 3, WithinAmount: 30, WithinUnit: UnitDay}, group.Conditions[1])
+		}
+		triggers, _ := SegmentTriggers(condition)
+		js, _ := json.Marshal(triggers)
+		is.JSONEq(string(expect), string(js))
+	}
+}
+
+func (ts *ConditionTestSuite) TestTriggersSetCondition_GivenCondition_ExpectCampaignTriggersRoundTrip() {
+	is := assert.New(ts.T())
+	body := &CampaignTriger{Triggers: &Triggers{DelayAmount: "1", DelayUnit: "day"}}
+
+	is.NoError(body.Triggers.SetCondition(And(HasTag("vip"), Attr("city", OpIn, []string{"BKK", "CNX"}))))
+
+	js, _ := json.Marshal(body)
+	fromPAM := &CampaignTriger{}
+	is.NoError(json.Unmarshal(js, fromPAM))
+	condition, err := fromPAM.Triggers.Condition()
+	if is.NoError(err) {
+		group := condition.(*GroupCondition)
+		is.Equal(LogicAnd, group.Logic)
+		is.Equal(&TagCondition{Tag: "vip", Has: true}, group.Conditions[0])
+		is.Equal([]interface{}{"BKK", "CNX"}, group.Conditions[1].(*AttributeCondition).Value)
+	}
+}
+
+func (ts *ConditionTestSuite) TestSegmentTriggers_GivenInvalidCondition_ExpectError() {
+	is := assert.New(ts.T())
+	invalid := []Condition{
+		nil,
+		And(),
+		Attr("age", OpGreater, nil),
+		Attr("age", Operator("between"), 1),
+		EventCount("purchase", 1).Within(1, TimeUnit("year")),
+		&EventCondition{Event: "purchase", Operator: OpContains},
+		CampaignInteraction("campaign_1", Interaction("liked")),
+		Or(HasTag("vip"), HasTag("")),
+	}
+	for _, c := range invalid {
+		_, err := SegmentTriggers(c)
+		is.Error(err)
+	}
+
+	_, err := ParseCondition("tag")
+	is.Error(err)
+}
+
+func (ts *ConditionTestSuite) TestCondition_GivenUnknownConditionsFromPAM_ExpectKeptAsRawAndWrittenBackUnchanged() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(nil, &RequestLogger{rq: mockRq, logger: NewMockLogger()})
+	mockRq.On("GetCtx", mock.Anything, "/triggers/5f1a7c2e9b1d4a0012ab34cd", mock.Anything).Return(ts.segmentFromPAM(), nil)
+
+	segment, _, err := sdk.GetSegmentByIDCtx(context.Background(), "5f1a7c2e9b1d4a0012ab34cd")
+	is.NoError(err)
+	expect, _ := json.Marshal(segment.Triggers)
+
+	condition, err := segment.Condition()
+	if is.NoError(err) {
+		group := condition.(*GroupCondition)
+		and := group.Conditions[0].(*GroupCondition)
+		is.Equal(&TagCondition{Tag: "shopper", Has: true}, and.Conditions[0])
+		is.Equal("location", and.Conditions[1].(*RawCondition).Type())
+		is.Equal("attribute", and.Conditions[2].(*RawCondition).Type())
+		is.Equal(&EventCondition{Event: "purchase", Operator: OpGreaterOrEqual, Count: 2, WithinAmount: 30, WithinUnit: UnitDay}, group.Conditions[1])
+		trigger := group.Conditions[2].(*TriggerCondition)
+		is.Equal("behavior", trigger.Type)
+		is.Equal(&EventCondition{Event: "add_to_cart", Operator: OpGreaterOrEqual, Count: 1}, trigger.Conditions[0])
+		is.Equal("tag", trigger.Conditions[1].(*RawCondition).Type())
+
+		updated := &Segment{}
+		is.NoError(updated.SetCondition(condition))
+		js, _ := json.Marshal(updated.Triggers)
+		is.JSONEq(string(expect), string(js))
+	}
+
+	expression, err := segment.Expression()
+	is.NoError(err)
+	is.JSONEq(string(expect), expression)
+}
+
+func (ts *ConditionTestSuite) TestSetCondition_GivenSegmentFromPAM_ExpectTriggersWrittenBackByteForByte() {
+	is := assert.New(ts.T())
+	var fixture struct {
+		Triggers json.RawMessage `json:"triggers"`
+	}
+	is.NoError(json.Unmarshal([]byte(ts.segmentFromPAM()), &fixture))
+	var expect bytes.Buffer
+	is.NoError(json.Compact(&expect, fixture.Triggers))
+
+	segment := &Segment{}
+	is.NoError(json.Unmarshal([]byte(ts.segmentFromPAM()), segment))
+	condition, err := segment.Condition()
+	if is.NoError(err) {
+		updated := &Segment{}
+		is.NoError(updated.SetCondition(condition))
+		js, _ := json.Marshal(updated.Triggers)
+		is.Equal(expect.String(), string(js))
+	}
+}
+
+func (ts *ConditionTestSuite) TestParseSegmentTriggers_GivenTriggerOfOtherType_ExpectTypeKept() {
+	is := assert.New(ts.T())
+	for _, kind := range []string{"", "or", "behavior"} {
+		triggers := []*SegmentTrigger{{Type: kind, Conditions: []interface{}{
+			map[string]interface{}{"type": "tag", "tag": "vip", "operator": "has"},
+			map[string]interface{}{"type": "tag", "tag": "gold", "operator": "has"},
+		}}}
+
+		condition, err := ParseSegmentTriggers(triggers)
+		if is.NoError(err, kind) {
+			is.Equal(&TriggerCondition{Type: kind, Conditions: []Condition{HasTag("vip"), HasTag("gold")}}, condition, kind)
+			written, err := SegmentTriggers(condition)
+			is.NoError(err, kind)
+			is.Equal(kind, written[0].Type)
+		}
+	}
+}
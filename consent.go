@@ -0,0 +1,199 @@
+package pam4sdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// EventConsentChange is event sent by UpdateConsent
+const EventConsentChange = "consent_change"
+
+// ConsentPurpose is purpose contact may consent to
+type ConsentPurpose string
+
+// Consent purposes
+const (
+	ConsentAnalytics ConsentPurpose = "analytics"
+	ConsentMarketing ConsentPurpose = "marketing"
+)
+
+// Consent is purposes contact granted, purposes not in the map are not granted
+type Consent map[ConsentPurpose]bool
+
+// Granted return true when purpose is granted
+func (c Consent) Granted(purpose ConsentPurpose) bool {
+	return c[purpose]
+}
+
+// ConsentDecision is what SDK did with event after checking consent
+type ConsentDecision string
+
+// Consent decisions
+const (
+	// ConsentSent means event was sent as is, also used when consent is not configured
+	ConsentSent ConsentDecision = "sent"
+	// ConsentDropped means event was not sent
+	ConsentDropped ConsentDecision = "dropped"
+	// ConsentAnonymized means event was sent without contact id, IP address and custom form fields
+	ConsentAnonymized ConsentDecision = "anonymized"
+)
+
+// ConsentProvider return consent of contact, it is called before every event so it should be cheap.
+// Anonymous visitors have empty contact id and can be told apart by SessionIDFromContext
+type ConsentProvider interface {
+	Consent(ctx context.Context, contactID string) (Consent, error)
+}
+
+// ConsentRecorder is ConsentProvider which UpdateConsent also keep up to date
+type ConsentRecorder interface {
+	ConsentProvider
+	SetConsent(ctx context.Context, contactID string, consent Consent) error
+}
+
+// ConsentProviderFunc adapt function to ConsentProvider
+type ConsentProviderFunc func(ctx context.Context, contactID string) (Consent, error)
+
+// Consent implement ConsentProvider
+func (f ConsentProviderFunc) Consent(ctx context.Context, contactID string) (Consent, error) {
+	return f(ctx, contactID)
+}
+
+// ConsentConfig is configuration of consent enforcement, zero fields use default value
+type ConsentConfig struct {
+	// Provider return consent of contact, nil disable enforcement
+	Provider ConsentProvider
+	// Purpose return purpose event is sent for, default is ConsentMarketing for events
+	// of a campaign and ConsentAnalytics for the others
+	Purpose func(tracker *Tracker, campaignID string) ConsentPurpose
+	// Anonymize send events of opted-out contacts anonymized instead of dropping them
+	Anonymize bool
+}
+
+// ConsentMetrics describe consent decision taken for an event
+type ConsentMetrics struct {
+	Event    string
+	Purpose  ConsentPurpose
+	Decision ConsentDecision
+}
+
+// ConsentMetricsCollector is MetricsCollector which also receive consent decisions
+type ConsentMetricsCollector interface {
+	ObserveConsent(m ConsentMetrics)
+}
+
+// UpdateConsent record consent in provider when it is ConsentRecorder then send consent change event to PAM.
+// The event is sent whatever consent was given so PAM keep the history of preferences
+func (sdk *Sdk) UpdateConsent(contactID string, consent Consent, tracker *Tracker) (string, error) {
+	_, raw, err := sdk.UpdateConsentCtx(context.Background(), contactID, consent, tracker)
+	return raw, err
+}
+
+// UpdateConsentCtx record consent and send consent change event to PAM with context
+func (sdk *Sdk) UpdateConsentCtx(ctx context.Context, contactID string, consent Consent, tracker *Tracker) (*EventResult, string, error) {
+	if recorder, ok := sdk.consent.Provider.(ConsentRecorder); ok {
+		if err := recorder.SetConsent(ctx, contactID, consent); err != nil {
+			return nil, "", NewErr(err)
+		}
+	}
+
+	t := Tracker{}
+	if tracker != nil {
+		t = *tracker
+	}
+	t.Event = EventConsentChange
+	t.FormFields = make(map[string]interface{}, len(t.FormFields)+len(consent))
+	if tracker != nil {
+		for key, value := range tracker.FormFields {
+			t.FormFields[key] = value
+		}
+	}
+	for purpose, granted := range consent {
+		t.FormFields["_consent_"+string(purpose)] = granted
+	}
+	return sdk.sendEvent(ctx, contactID, "", "", &t, false)
+}
+
+// checkConsent return decision for event of contact, provider error is treated as consent not given
+func (sdk *Sdk) checkConsent(ctx context.Context, logger ILogger, contactID string, campaignID string, tracker *Tracker) ConsentDecision {
+	config := sdk.consent
+	if config.Provider == nil {
+		return ConsentSent
+	}
+	purpose := ConsentAnalytics
+	if config.Purpose != nil {
+		purpose = config.Purpose(tracker, campaignID)
+	} else if len(campaignID) > 0 {
+		purpose = ConsentMarketing
+	}
+
+	consent, err := config.Provider.Consent(ctx, contactID)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("[PAM CONSENT] cannot load consent of contact %q: %s", contactID, err.Error()))
+	}
+	decision := ConsentSent
+	if err != nil || !consent.Granted(purpose) {
+		decision = ConsentDropped
+		if config.Anonymize {
+			decision = ConsentAnonymized
+		}
+		logger.Info(fmt.Sprintf("[PAM CONSENT] %s event %s of contact %q, %s is not granted", decision, tracker.Event, contactID, purpose))
+	}
+
+	if collector, ok := sdk.metrics.(ConsentMetricsCollector); ok {
+		collector.ObserveConsent(ConsentMetrics{Event: tracker.Event, Purpose: purpose, Decision: decision})
+	}
+	return decision
+}
+
+// anonymize remove fields identifying contact from t, form fields reserved by PAM are kept.
+// User agent is removed too because together with page URL it fingerprint the visitor
+func anonymize(t *Tracker) {
+	t.IPAddress = ""
+	t.UserAgent = ""
+	t.QueryString = ""
+	if i := strings.IndexAny(t.PageURL, "?#"); i >= 0 {
+		t.PageURL = t.PageURL[:i]
+	}
+	for key := range t.FormFields {
+		if !strings.HasPrefix(key, "_") {
+			delete(t.FormFields, key)
+		}
+	}
+}
+
+// MemoryConsentProvider keep consent in memory, contacts without consent get Default
+type MemoryConsentProvider struct {
+	mu       sync.RWMutex
+	consents map[string]Consent
+	// Default is consent of contacts which never updated their preferences
+	Default Consent
+}
+
+// NewMemoryConsentProvider return provider giving defaultConsent to unknown contacts
+func NewMemoryConsentProvider(defaultConsent Consent) *MemoryConsentProvider {
+	return &MemoryConsentProvider{consents: map[string]Consent{}, Default: defaultConsent}
+}
+
+// Consent implement ConsentProvider
+func (p *MemoryConsentProvider) Consent(ctx context.Context, contactID string) (Consent, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if consent, ok := p.consents[contactID]; ok {
+		return consent, nil
+	}
+	return p.Default, nil
+}
+
+// SetConsent implement ConsentRecorder
+func (p *MemoryConsentProvider) SetConsent(ctx context.Context, contactID string, consent Consent) error {
+	c := make(Consent, len(consent))
+	for purpose, granted := range consent {
+		c[purpose] = granted
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.consents[contactID] = c
+	return nil
+}
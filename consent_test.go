@@ -0,0 +1,149 @@
+package pam4sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type ConsentTestSuite struct {
+	suite.Suite
+	server   *httptest.Server
+	received []map[string]interface{}
+	cookies  []string
+}
+
+func TestConsentTestSuite(t *testing.T) {
+	suite.Run(t, new(ConsentTestSuite))
+}
+
+func (ts *ConsentTestSuite) SetupTest() {
+	ts.received = nil
+	ts.cookies = nil
+	ts.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&p)
+		ts.received = append(ts.received, p)
+		c, _ := r.Cookie(ContactIDCookie)
+		ts.cookies = append(ts.cookies, c.Value)
+		w.Write([]byte(`{"contact_id":"contact_from_pam"}`))
+	}))
+}
+
+func (ts *ConsentTestSuite) TearDownTest() {
+	ts.server.Close()
+}
+
+func (ts *ConsentTestSuite) client(config ConsentConfig, metrics *ExpvarMetrics) *Sdk {
+	return NewClient(
+		WithConnect(&SDKConnector{BaseURL: ts.server.URL, AppID: "app", AppSecret: "secret"}),
+		WithConsent(config),
+		WithMetrics(metrics),
+	)
+}
+
+func (ts *ConsentTestSuite) tracker() *Tracker {
+	return &Tracker{
+		Event:      "view",
+		PageURL:    "https://shop.example.com/p?email=a@example.com",
+		UserAgent:  "Mozilla/5.0 (X11; Linux x86_64) Firefox/118.0",
+		IPAddress:  "10.0.0.1",
+		FormFields: map[string]interface{}{"email": "a@example.com"},
+	}
+}
+
+func (ts *ConsentTestSuite) TestSendEventCtx_GivenPurposeNotGranted_ExpectEventDroppedAndCounted() {
+	is := assert.New(ts.T())
+	metrics := NewExpvarMetrics()
+	provider := NewMemoryConsentProvider(Consent{ConsentAnalytics: true})
+	sdk := ts.client(ConsentConfig{Provider: provider}, metrics)
+
+	res, _, err := sdk.SendEventCtx(context.Background(), "contact_1", "campaign_1", ts.tracker())
+	is.NoError(err)
+	is.Equal(ConsentDropped, res.Consent)
+	is.Equal("contact_1", res.ContactID)
+
+	res, _, err = sdk.SendEventCtx(context.Background(), "contact_1", "", ts.tracker())
+	is.NoError(err)
+	is.Equal(ConsentSent, res.Consent)
+	is.Equal("contact_from_pam", res.ContactID)
+
+	is.Len(ts.received, 1)
+	is.Equal(map[string]int64{"marketing dropped": 1, "analytics sent": 1}, metrics.ConsentSnapshot())
+}
+
+func (ts *ConsentTestSuite) TestSendEventCtx_GivenRecordingTracer_ExpectDecisionRecordedForDroppedEvent() {
+	is := assert.New(ts.T())
+	tracer := NewRecordingTracer()
+	sdk := NewClient(
+		WithConnect(&SDKConnector{BaseURL: ts.server.URL, AppID: "app", AppSecret: "secret"}),
+		WithConsent(ConsentConfig{Provider: NewMemoryConsentProvider(Consent{})}),
+		WithTracer(tracer),
+	)
+
+	_, _, err := sdk.SendEventCtx(context.Background(), "contact_1", "campaign_1", ts.tracker())
+	is.NoError(err)
+
+	is.Empty(ts.received)
+	spans := tracer.Spans()
+	if is.Len(spans, 1) {
+		is.Equal(string(ConsentDropped), spans[0].Attributes["pam.consent"])
+	}
+}
+
+func (ts *ConsentTestSuite) TestSendEventCtx_GivenAnonymize_ExpectIdentifyingFieldsRemoved() {
+	is := assert.New(ts.T())
+	provider := ConsentProviderFunc(func(ctx context.Context, contactID string) (Consent, error) {
+		return nil, errors.New("consent service is down")
+	})
+	metrics := NewExpvarMetrics()
+	sdk := ts.client(ConsentConfig{Provider: provider, Anonymize: true}, metrics)
+	tracker := ts.tracker()
+
+	res, _, err := sdk.SendEventCtx(context.Background(), "contact_1", "", tracker)
+	is.NoError(err)
+	is.Equal(ConsentAnonymized, res.Consent)
+	is.Equal("contact_1", res.ContactID)
+
+	if is.Len(ts.received, 1) {
+		p := ts.received[0]
+		is.Equal("", ts.cookies[0])
+		is.Equal("", p["ip_address"])
+		is.Equal("", p["useragent"])
+		is.Equal("https://shop.example.com/p", p["page_url"])
+		is.Empty(p["form_fields"])
+	}
+	is.Equal("a@example.com", tracker.FormFields["email"])
+	is.Equal(map[string]int64{"analytics anonymized": 1}, metrics.ConsentSnapshot())
+}
+
+func (ts *ConsentTestSuite) TestUpdateConsentCtx_GivenOptOut_ExpectConsentChangeSentAndRecorded() {
+	is := assert.New(ts.T())
+	provider := NewMemoryConsentProvider(Consent{ConsentAnalytics: true, ConsentMarketing: true})
+	sdk := ts.client(ConsentConfig{Provider: provider}, NewExpvarMetrics())
+
+	_, _, err := sdk.UpdateConsentCtx(context.Background(), "contact_1", Consent{ConsentAnalytics: false, ConsentMarketing: false}, nil)
+	is.NoError(err)
+
+	if is.Len(ts.received, 1) {
+		p := ts.received[0]
+		is.Equal(EventConsentChange, p["event"])
+		is.Equal("contact_1", ts.cookies[0])
+		fields := p["form_fields"].(map[string]interface{})
+		is.Equal(false, fields["_consent_analytics"])
+		is.Equal(false, fields["_consent_marketing"])
+	}
+	consent, _ := provider.Consent(context.Background(), "contact_1")
+	is.False(consent.Granted(ConsentAnalytics))
+
+	res, _, err := sdk.SendEventCtx(context.Background(), "contact_1", "", ts.tracker())
+	is.NoError(err)
+	is.Equal(ConsentDropped, res.Consent)
+	is.Len(ts.received, 1)
+}
@@ -0,0 +1,55 @@
+package pam4sdk
+
+import (
+	"context"
+	"sync"
+)
+
+// ContactStore persist contact id resolved by PAM so anonymous visitors keep a stable contact across calls.
+// It is used only when ctx carry session id set by ContextWithSessionID. It must be safe for concurrent use
+type ContactStore interface {
+	// LoadContactID return contact id of session, empty string when session is unknown
+	LoadContactID(ctx context.Context, sessionID string) (string, error)
+	// SaveContactID remember contact id of session
+	SaveContactID(ctx context.Context, sessionID string, contactID string) error
+}
+
+type sessionIDKey struct{}
+
+// ContextWithSessionID return ctx carrying session id used as key of ContactStore
+func ContextWithSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionIDKey{}, sessionID)
+}
+
+// SessionIDFromContext return session id carried by ctx, it is empty when not set.
+// Contact store is not used for events without session so unrelated visitors are never merged
+func SessionIDFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(sessionIDKey{}).(string)
+	return sessionID
+}
+
+// MemoryContactStore keep contact ids in memory, they are lost when process exit
+type MemoryContactStore struct {
+	mu       sync.RWMutex
+	contacts map[string]string
+}
+
+// NewMemoryContactStore return empty MemoryContactStore
+func NewMemoryContactStore() *MemoryContactStore {
+	return &MemoryContactStore{contacts: map[string]string{}}
+}
+
+// LoadContactID implement ContactStore
+func (s *MemoryContactStore) LoadContactID(ctx context.Context, sessionID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.contacts[sessionID], nil
+}
+
+// SaveContactID implement ContactStore
+func (s *MemoryContactStore) SaveContactID(ctx context.Context, sessionID string, contactID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.contacts[sessionID] = contactID
+	return nil
+}
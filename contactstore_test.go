@@ -0,0 +1,40 @@
+package pam4sdk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type ContactStoreTestSuite struct {
+	suite.Suite
+}
+
+func TestContactStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(ContactStoreTestSuite))
+}
+
+func (ts *ContactStoreTestSuite) TestMemoryContactStore_GivenSessions_ExpectContactIDsKeptSeparately() {
+	is := assert.New(ts.T())
+	ctx := context.Background()
+	store := NewMemoryContactStore()
+
+	is.NoError(store.SaveContactID(ctx, "alice", "contact_1"))
+	is.NoError(store.SaveContactID(ctx, "", "contact_default"))
+
+	contactID, err := store.LoadContactID(ctx, "alice")
+	is.NoError(err)
+	is.Equal("contact_1", contactID)
+	contactID, _ = store.LoadContactID(ctx, "")
+	is.Equal("contact_default", contactID)
+	contactID, _ = store.LoadContactID(ctx, "bob")
+	is.Empty(contactID)
+}
+
+func (ts *ContactStoreTestSuite) TestSessionIDFromContext_GivenNoSession_ExpectEmpty() {
+	is := assert.New(ts.T())
+	is.Empty(SessionIDFromContext(context.Background()))
+	is.Equal("alice", SessionIDFromContext(ContextWithSessionID(context.Background(), "alice")))
+}
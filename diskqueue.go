@@ -0,0 +1,579 @@
+package pam4sdk
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDiskQueueFull is returned by Append when queue reach MaxBytes
+var ErrDiskQueueFull = errors.New("pam disk queue is full")
+
+// ErrDiskQueueClosed is returned when using queue after Close
+var ErrDiskQueueClosed = errors.New("pam disk queue is closed")
+
+// SyncPolicy decide when DiskQueue fsync its files
+type SyncPolicy int
+
+// Sync policies
+const (
+	// SyncAlways fsync after every append and ack, no acknowledged write is lost on power failure
+	SyncAlways SyncPolicy = iota
+	// SyncInterval fsync in background every SyncInterval
+	SyncInterval
+	// SyncNever leave flushing to the operating system, a crash of the process still lose nothing
+	SyncNever
+)
+
+const (
+	diskSegmentExt    = ".seg"
+	diskAckFile       = "ack"
+	diskRecordHeader  = 16
+	diskReplayBatch   = 100
+	diskMaxRecordSize = 16 << 20
+)
+
+// DiskQueueConfig is configuration of DiskQueue, zero fields use default value
+type DiskQueueConfig struct {
+	// Dir is directory holding segment files, it is created when missing
+	Dir string
+	// MaxSegmentBytes is size that make queue start a new segment file, default 16MB
+	MaxSegmentBytes int64
+	// MaxBytes is maximum size of all segment files, 0 is unlimited
+	MaxBytes int64
+	// Sync is fsync policy, default SyncAlways
+	Sync SyncPolicy
+	// SyncInterval is fsync period of SyncInterval policy, default 1 second
+	SyncInterval time.Duration
+	// OnDeadLetter is called with replayed event rejected by PAM with non retryable error,
+	// the event is acknowledged so it does not block the queue
+	OnDeadLetter func(event *Event, err error)
+	// OnError is called with error which stopped Replay run by Run, such as PAM being unreachable
+	OnError func(err error)
+}
+
+// QueuedEvent is event stored in DiskQueue with its sequence number
+type QueuedEvent struct {
+	Seq   uint64
+	Event *Event
+}
+
+// DiskQueue is append-only segment log of events which survive restart, it is safe for concurrent use.
+// Events are replayed in order and removed once acknowledged
+type DiskQueue struct {
+	config DiskQueueConfig
+
+	mu       sync.Mutex
+	segments []*diskSegment
+	file     *os.File
+	acked    uint64
+	nextSeq  uint64
+	closed   bool
+	notify   chan struct{}
+	stopSync chan struct{}
+	syncDone chan struct{}
+}
+
+type diskSegment struct {
+	path     string
+	firstSeq uint64
+	lastSeq  uint64
+	size     int64
+	// ackedOffset is offset of the first record which may not be acknowledged,
+	// Pending read from it so acknowledged records are not read again
+	ackedOffset int64
+	// readSeq and readOffset are sequence and end offset of the last record returned by Pending
+	readSeq    uint64
+	readOffset int64
+}
+
+func (s *diskSegment) empty() bool {
+	return s.lastSeq < s.firstSeq
+}
+
+// OpenDiskQueue open queue in config.Dir, recovering records written before a crash.
+// Partially written record at the end of a segment is truncated
+func OpenDiskQueue(config DiskQueueConfig) (*DiskQueue, error) {
+	if len(config.Dir) == 0 {
+		return nil, errors.New("pam disk queue dir is required")
+	}
+	if config.MaxSegmentBytes <= 0 {
+		config.MaxSegmentBytes = 16 << 20
+	}
+	if config.SyncInterval <= 0 {
+		config.SyncInterval = time.Second
+	}
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	q := &DiskQueue{
+		config: config,
+		notify: make(chan struct{}, 1),
+	}
+	if err := q.recover(); err != nil {
+		return nil, err
+	}
+	if config.Sync == SyncInterval {
+		q.stopSync = make(chan struct{})
+		q.syncDone = make(chan struct{})
+		go q.syncLoop()
+	}
+	return q, nil
+}
+
+func (q *DiskQueue) recover() error {
+	acked, err := q.readAck()
+	if err != nil {
+		return err
+	}
+	q.acked = acked
+	q.nextSeq = acked + 1
+
+	paths, err := filepath.Glob(filepath.Join(q.config.Dir, "*"+diskSegmentExt))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		first, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(path), diskSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		seg := &diskSegment{path: path, firstSeq: first, lastSeq: first - 1}
+		if err := scanSegment(seg, nil); err != nil {
+			return err
+		}
+		if seg.lastSeq+1 > q.nextSeq {
+			q.nextSeq = seg.lastSeq + 1
+		}
+		q.segments = append(q.segments, seg)
+	}
+
+	if len(q.segments) == 0 {
+		return q.rotate()
+	}
+	last := q.segments[len(q.segments)-1]
+	q.file, err = os.OpenFile(last.path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	return q.compact()
+}
+
+// scanSegment read records of seg to find its last sequence and valid size,
+// the file is truncated after the last valid record. fn receive every valid record when not nil
+func scanSegment(seg *diskSegment, fn func(seq uint64, payload []byte) bool) error {
+	f, err := os.OpenFile(seg.path, os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := &countingReader{r: f}
+	header := make([]byte, diskRecordHeader)
+	for {
+		offset := r.n
+		if _, err := io.ReadFull(r, header); err != nil {
+			return truncateSegment(f, seg, offset)
+		}
+		seq := binary.BigEndian.Uint64(header[0:8])
+		length := binary.BigEndian.Uint32(header[8:12])
+		sum := binary.BigEndian.Uint32(header[12:16])
+		if length > diskMaxRecordSize {
+			return truncateSegment(f, seg, offset)
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(r, payload); err != nil || crc32.ChecksumIEEE(payload) != sum {
+			return truncateSegment(f, seg, offset)
+		}
+		seg.lastSeq = seq
+		if fn != nil && !fn(seq, payload) {
+			return nil
+		}
+	}
+}
+
+// readSegment read records of segment at path from offset without changing the file,
+// it stop at the end of valid records. fn receive every record with its end offset
+func readSegment(path string, offset int64, fn func(seq uint64, payload []byte, end int64) bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(f)
+	header := make([]byte, diskRecordHeader)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil
+		}
+		seq := binary.BigEndian.Uint64(header[0:8])
+		length := binary.BigEndian.Uint32(header[8:12])
+		sum := binary.BigEndian.Uint32(header[12:16])
+		if length > diskMaxRecordSize {
+			return nil
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(r, payload); err != nil || crc32.ChecksumIEEE(payload) != sum {
+			return nil
+		}
+		offset += int64(diskRecordHeader) + int64(length)
+		if !fn(seq, payload, offset) {
+			return nil
+		}
+	}
+}
+
+func truncateSegment(f *os.File, seg *diskSegment, size int64) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	seg.size = size
+	if info.Size() == size {
+		return nil
+	}
+	return f.Truncate(size)
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Append write event to the end of queue and return its sequence number
+func (q *DiskQueue) Append(event *Event) (uint64, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+	record := make([]byte, diskRecordHeader+len(payload))
+	copy(record[diskRecordHeader:], payload)
+	binary.BigEndian.PutUint32(record[8:12], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[12:16], crc32.ChecksumIEEE(payload))
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return 0, ErrDiskQueueClosed
+	}
+	active := q.segments[len(q.segments)-1]
+	if q.config.MaxBytes > 0 && q.size()+int64(len(record)) > q.config.MaxBytes {
+		// Active segment may hold only acknowledged events, start a new one so it can be removed
+		if active.empty() || active.lastSeq > q.acked {
+			return 0, ErrDiskQueueFull
+		}
+		if err := q.rotate(); err != nil {
+			return 0, err
+		}
+		if err := q.compact(); err != nil {
+			return 0, err
+		}
+		active = q.segments[len(q.segments)-1]
+		if q.size()+int64(len(record)) > q.config.MaxBytes {
+			return 0, ErrDiskQueueFull
+		}
+	}
+	if active.size > 0 && active.size+int64(len(record)) > q.config.MaxSegmentBytes {
+		if err := q.rotate(); err != nil {
+			return 0, err
+		}
+		active = q.segments[len(q.segments)-1]
+	}
+
+	seq := q.nextSeq
+	binary.BigEndian.PutUint64(record[0:8], seq)
+	if _, err := q.file.Write(record); err != nil {
+		// Cut the partial record so the next append start at a record boundary
+		q.file.Truncate(active.size)
+		return 0, err
+	}
+	if q.config.Sync == SyncAlways {
+		if err := q.file.Sync(); err != nil {
+			return 0, err
+		}
+	}
+	active.size += int64(len(record))
+	active.lastSeq = seq
+	q.nextSeq++
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return seq, nil
+}
+
+// Pending return up to limit events which are not acknowledged yet, in order
+func (q *DiskQueue) Pending(limit int) ([]*QueuedEvent, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil, ErrDiskQueueClosed
+	}
+
+	var events []*QueuedEvent
+	var decodeErr error
+	for _, seg := range q.segments {
+		if seg.empty() || seg.lastSeq <= q.acked {
+			continue
+		}
+		if seg.readSeq <= q.acked && seg.readOffset > seg.ackedOffset {
+			seg.ackedOffset = seg.readOffset
+		}
+		err := readSegment(seg.path, seg.ackedOffset, func(seq uint64, payload []byte, end int64) bool {
+			if seq <= q.acked {
+				seg.ackedOffset = end
+				return true
+			}
+			event := &Event{}
+			if err := json.Unmarshal(payload, event); err != nil {
+				decodeErr = err
+				return false
+			}
+			events = append(events, &QueuedEvent{Seq: seq, Event: event})
+			seg.readSeq, seg.readOffset = seq, end
+			return len(events) < limit
+		})
+		if err != nil {
+			return nil, err
+		}
+		if decodeErr != nil {
+			return nil, decodeErr
+		}
+		if len(events) >= limit {
+			break
+		}
+	}
+	return events, nil
+}
+
+// Len return number of events which are not acknowledged yet
+func (q *DiskQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.nextSeq <= q.acked+1 {
+		return 0
+	}
+	return int(q.nextSeq - q.acked - 1)
+}
+
+// Ack acknowledge every event up to seq, acknowledged segments are removed from disk
+func (q *DiskQueue) Ack(seq uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrDiskQueueClosed
+	}
+	if seq <= q.acked {
+		return nil
+	}
+	if seq >= q.nextSeq {
+		return fmt.Errorf("pam disk queue ack %d is beyond last sequence %d", seq, q.nextSeq-1)
+	}
+	if err := q.writeAck(seq); err != nil {
+		return err
+	}
+	q.acked = seq
+	return q.compact()
+}
+
+// Replay send pending events in order and acknowledge each of them once it is sent, it stop at the
+// first retryable error. Events rejected with non retryable error are given to OnDeadLetter and acknowledged
+func (q *DiskQueue) Replay(ctx context.Context, sender EventSender) (int, error) {
+	sent := 0
+	for {
+		events, err := q.Pending(diskReplayBatch)
+		if err != nil || len(events) == 0 {
+			return sent, err
+		}
+		for _, qe := range events {
+			e := qe.Event
+			_, _, err := sender.SendEventTransactionCtx(ctx, e.ContactID, e.CampaignID, e.TransactionID, e.Tracker)
+			if err != nil {
+				if ctx.Err() != nil || IsRetryable(err) {
+					return sent, err
+				}
+				if q.config.OnDeadLetter != nil {
+					q.config.OnDeadLetter(e, err)
+				}
+			} else {
+				sent++
+			}
+			if err := q.Ack(qe.Seq); err != nil {
+				return sent, err
+			}
+		}
+	}
+}
+
+// Run replay events whenever they are appended and retry every interval until ctx is done,
+// so queued events are sent in order once PAM is reachable again. Replay errors are given to OnError
+func (q *DiskQueue) Run(ctx context.Context, sender EventSender, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := q.Replay(ctx, sender); err != nil && ctx.Err() == nil && q.config.OnError != nil {
+			q.config.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-q.notify:
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync fsync active segment
+func (q *DiskQueue) Sync() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrDiskQueueClosed
+	}
+	return q.file.Sync()
+}
+
+// Close sync and close files, pending events are kept for the next OpenDiskQueue
+func (q *DiskQueue) Close() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return ErrDiskQueueClosed
+	}
+	q.closed = true
+	q.mu.Unlock()
+
+	if q.stopSync != nil {
+		close(q.stopSync)
+		<-q.syncDone
+	}
+	if err := q.file.Sync(); err != nil {
+		q.file.Close()
+		return err
+	}
+	return q.file.Close()
+}
+
+func (q *DiskQueue) syncLoop() {
+	defer close(q.syncDone)
+	ticker := time.NewTicker(q.config.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.stopSync:
+			return
+		case <-ticker.C:
+			q.Sync()
+		}
+	}
+}
+
+// rotate start new active segment at nextSeq, it must be called with mu held
+func (q *DiskQueue) rotate() error {
+	if q.file != nil {
+		if err := q.file.Sync(); err != nil {
+			return err
+		}
+		if err := q.file.Close(); err != nil {
+			return err
+		}
+	}
+	path := filepath.Join(q.config.Dir, fmt.Sprintf("%020d%s", q.nextSeq, diskSegmentExt))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	q.file = f
+	q.segments = append(q.segments, &diskSegment{path: path, firstSeq: q.nextSeq, lastSeq: q.nextSeq - 1})
+	return nil
+}
+
+// compact remove segments whose events are all acknowledged, the active segment is kept
+func (q *DiskQueue) compact() error {
+	for len(q.segments) > 1 {
+		seg := q.segments[0]
+		if !seg.empty() && seg.lastSeq > q.acked {
+			return nil
+		}
+		if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		q.segments = q.segments[1:]
+	}
+	return nil
+}
+
+func (q *DiskQueue) size() int64 {
+	var size int64
+	for _, seg := range q.segments {
+		size += seg.size
+	}
+	return size
+}
+
+func (q *DiskQueue) readAck() (uint64, error) {
+	b, err := ioutil.ReadFile(filepath.Join(q.config.Dir, diskAckFile))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(b) != 8 {
+		return 0, fmt.Errorf("pam disk queue ack file is corrupted")
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// writeAck replace ack file atomically
+func (q *DiskQueue) writeAck(seq uint64) error {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, seq)
+	path := filepath.Join(q.config.Dir, diskAckFile)
+	tmp := path + ".tmp"
+
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if q.config.Sync == SyncAlways {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,251 @@
+package pam4sdk
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type DiskQueueTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestDiskQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(DiskQueueTestSuite))
+}
+
+func (ts *DiskQueueTestSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "pam4sdk-queue")
+	ts.Require().NoError(err)
+	ts.dir = dir
+}
+
+func (ts *DiskQueueTestSuite) TearDownTest() {
+	os.RemoveAll(ts.dir)
+}
+
+func (ts *DiskQueueTestSuite) open(config DiskQueueConfig) *DiskQueue {
+	config.Dir = ts.dir
+	q, err := OpenDiskQueue(config)
+	ts.Require().NoError(err)
+	return q
+}
+
+func (ts *DiskQueueTestSuite) appendEvents(q *DiskQueue, names ...string) {
+	for _, name := range names {
+		_, err := q.Append(event(name))
+		ts.Require().NoError(err)
+	}
+}
+
+func (ts *DiskQueueTestSuite) pendingNames(q *DiskQueue) []string {
+	events, err := q.Pending(100)
+	ts.Require().NoError(err)
+	var names []string
+	for _, qe := range events {
+		names = append(names, qe.Event.Tracker.Event)
+	}
+	return names
+}
+
+func (ts *DiskQueueTestSuite) segmentFiles() []string {
+	paths, _ := filepath.Glob(filepath.Join(ts.dir, "*"+diskSegmentExt))
+	return paths
+}
+
+func (ts *DiskQueueTestSuite) TestReplay_GivenAppendedEvents_ExpectSentInOrderAndAcknowledged() {
+	is := assert.New(ts.T())
+	q := ts.open(DiskQueueConfig{})
+	ts.appendEvents(q, "e1", "e2", "e3")
+	is.Equal(3, q.Len())
+
+	sender := &fakeSender{}
+	sent, err := q.Replay(context.Background(), sender)
+	is.NoError(err)
+	is.Equal(3, sent)
+	is.Equal([]string{"e1", "e2", "e3"}, sender.events())
+	is.Equal(0, q.Len())
+	is.NoError(q.Close())
+
+	q = ts.open(DiskQueueConfig{})
+	defer q.Close()
+	is.Empty(ts.pendingNames(q))
+}
+
+func (ts *DiskQueueTestSuite) TestOpenDiskQueue_GivenUnacknowledgedEvents_ExpectRecoveredAfterRestart() {
+	is := assert.New(ts.T())
+	q := ts.open(DiskQueueConfig{Sync: SyncNever})
+	ts.appendEvents(q, "e1", "e2", "e3")
+	is.NoError(q.Ack(1))
+	is.NoError(q.Close())
+
+	q = ts.open(DiskQueueConfig{Sync: SyncNever})
+	defer q.Close()
+	is.Equal([]string{"e2", "e3"}, ts.pendingNames(q))
+	seq, err := q.Append(event("e4"))
+	is.NoError(err)
+	is.Equal(uint64(4), seq)
+}
+
+func (ts *DiskQueueTestSuite) TestOpenDiskQueue_GivenTornWrite_ExpectPartialRecordTruncated() {
+	is := assert.New(ts.T())
+	q := ts.open(DiskQueueConfig{})
+	ts.appendEvents(q, "e1", "e2")
+	is.NoError(q.Close())
+
+	// Simulate crash in the middle of writing a record
+	paths := ts.segmentFiles()
+	f, err := os.OpenFile(paths[len(paths)-1], os.O_WRONLY|os.O_APPEND, 0644)
+	is.NoError(err)
+	f.Write([]byte{0, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0, 99, 1, 2})
+	f.Close()
+
+	q = ts.open(DiskQueueConfig{})
+	defer q.Close()
+	ts.appendEvents(q, "e3")
+	is.Equal([]string{"e1", "e2", "e3"}, ts.pendingNames(q))
+}
+
+func (ts *DiskQueueTestSuite) TestAck_GivenRotatedSegments_ExpectAcknowledgedSegmentsRemoved() {
+	is := assert.New(ts.T())
+	q := ts.open(DiskQueueConfig{MaxSegmentBytes: 1})
+	defer q.Close()
+	ts.appendEvents(q, "e1", "e2", "e3")
+	is.Len(ts.segmentFiles(), 3)
+
+	is.NoError(q.Ack(2))
+	is.Len(ts.segmentFiles(), 1)
+	is.Equal([]string{"e3"}, ts.pendingNames(q))
+}
+
+func (ts *DiskQueueTestSuite) TestAppend_GivenMaxBytesReached_ExpectQueueFull() {
+	is := assert.New(ts.T())
+	q := ts.open(DiskQueueConfig{MaxBytes: 2000})
+	defer q.Close()
+
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		_, err = q.Append(event("e"))
+	}
+	is.Equal(ErrDiskQueueFull, err)
+
+	// Acknowledging everything free space for new events
+	is.NoError(q.Ack(uint64(q.Len())))
+	_, err = q.Append(event("e"))
+	is.NoError(err)
+}
+
+func (ts *DiskQueueTestSuite) TestReplay_GivenRetryableAndPermanentErrors_ExpectStopAtRetryableOnly() {
+	is := assert.New(ts.T())
+	var dead []string
+	q := ts.open(DiskQueueConfig{OnDeadLetter: func(event *Event, err error) {
+		dead = append(dead, event.Tracker.Event)
+	}})
+	defer q.Close()
+	ts.appendEvents(q, "bad", "down", "e3")
+
+	sender := &fakeSender{fn: func(e string) error {
+		switch e {
+		case "bad":
+			return &APIError{StatusCode: http.StatusBadRequest}
+		case "down":
+			return &APIError{StatusCode: http.StatusServiceUnavailable}
+		}
+		return nil
+	}}
+	sent, err := q.Replay(context.Background(), sender)
+	is.Error(err)
+	is.Equal(0, sent)
+	is.Equal([]string{"bad"}, dead)
+	is.Equal([]string{"down", "e3"}, ts.pendingNames(q))
+
+	sender.fn = nil
+	sent, err = q.Replay(context.Background(), sender)
+	is.NoError(err)
+	is.Equal(2, sent)
+	is.Equal([]string{"down", "e3"}, sender.events())
+}
+
+func (ts *DiskQueueTestSuite) TestReplay_GivenLargeBacklog_ExpectEachEventAcknowledgedOnceSent() {
+	is := assert.New(ts.T())
+	q := ts.open(DiskQueueConfig{})
+	defer q.Close()
+	total := 2*diskReplayBatch + 50
+	for i := 0; i < total; i++ {
+		ts.appendEvents(q, fmt.Sprintf("e%d", i))
+	}
+
+	var lens []int
+	sender := &fakeSender{fn: func(e string) error {
+		lens = append(lens, q.Len())
+		if e == "e150" {
+			return &APIError{StatusCode: http.StatusServiceUnavailable}
+		}
+		return nil
+	}}
+	sent, err := q.Replay(context.Background(), sender)
+
+	is.Error(err)
+	is.Equal(150, sent)
+	for i, n := range lens {
+		is.Equal(total-i, n)
+	}
+	is.Equal(total-150, q.Len())
+}
+
+func (ts *DiskQueueTestSuite) TestRun_GivenReplayError_ExpectOnErrorCalled() {
+	is := assert.New(ts.T())
+	errs := make(chan error, 1)
+	q := ts.open(DiskQueueConfig{OnError: func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}})
+	defer q.Close()
+	ts.appendEvents(q, "down")
+	sender := &fakeSender{fn: func(e string) error {
+		return &APIError{StatusCode: http.StatusServiceUnavailable}
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx, sender, time.Hour)
+
+	select {
+	case err := <-errs:
+		is.True(IsRetryable(err))
+	case <-time.After(time.Second):
+		is.Fail("OnError was not called")
+	}
+	is.Equal(1, q.Len())
+}
+
+func (ts *DiskQueueTestSuite) TestPending_GivenAcknowledgedEvents_ExpectReadResumedAfterThem() {
+	is := assert.New(ts.T())
+	q := ts.open(DiskQueueConfig{})
+	defer q.Close()
+	ts.appendEvents(q, "e1", "e2", "e3", "e4")
+
+	events, err := q.Pending(2)
+	is.NoError(err)
+	is.NoError(q.Ack(events[1].Seq))
+	is.Equal([]string{"e3", "e4"}, ts.pendingNames(q))
+
+	seg := q.segments[0]
+	is.Equal(seg.size, seg.readOffset)
+	offset := seg.ackedOffset
+	is.True(offset > 0 && offset < seg.size)
+	is.NoError(q.Ack(events[1].Seq + 1))
+	is.Equal([]string{"e4"}, ts.pendingNames(q))
+	is.True(seg.ackedOffset > offset && seg.ackedOffset < seg.size)
+}
@@ -0,0 +1,347 @@
+package pam4sdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrDispatcherClosed is returned by Dispatch after Close was called
+var ErrDispatcherClosed = errors.New("pam event dispatcher is closed")
+
+// ErrEventDropped is given to OnDeadLetter when event is dropped because queue is full
+var ErrEventDropped = errors.New("pam event dropped because queue is full")
+
+// Event is tracker event queued by EventDispatcher
+type Event struct {
+	ContactID     string
+	CampaignID    string
+	TransactionID string
+	Tracker       *Tracker
+}
+
+// EventSender send a single event, Sdk implement it
+type EventSender interface {
+	SendEventTransactionCtx(ctx context.Context, contactID string, campaignID string, transactionID string, tracker *Tracker) (*EventResult, string, error)
+}
+
+// retryingSender is EventSender which retry events by itself
+type retryingSender interface {
+	retriesEvents() bool
+}
+
+// Backpressure decide what Dispatch do when queue is full
+type Backpressure int
+
+// Backpressure policies
+const (
+	// BackpressureBlock make Dispatch wait until there is room or its context is done
+	BackpressureBlock Backpressure = iota
+	// BackpressureDropOldest remove the oldest queued event to make room
+	BackpressureDropOldest
+	// BackpressureDropNewest reject the event being dispatched
+	BackpressureDropNewest
+)
+
+// EventDispatcherConfig is configuration of EventDispatcher, zero fields use default value
+type EventDispatcherConfig struct {
+	// BufferSize is maximum number of queued events, default 1000
+	BufferSize int
+	// BatchSize is number of queued events that trigger a flush, default 50
+	BatchSize int
+	// FlushInterval is maximum time an event wait in queue, default 1 second
+	FlushInterval time.Duration
+	// Workers is number of goroutines sending events, default 1
+	Workers int
+	// Backpressure is policy applied when queue is full, default BackpressureBlock
+	Backpressure Backpressure
+	// Retry resend event failed with network error, 408, 429 or 5xx, nil disable retry.
+	// Only events having TransactionID are resent so PAM can drop duplicates, and events are
+	// not resent when sender already retry them, see Sdk with WithRetryPolicy
+	Retry *RetryPolicy
+	// OnDeadLetter is called with event which could not be sent or was dropped
+	OnDeadLetter func(event *Event, err error)
+}
+
+// EventDispatcher queue events in memory and send them in background, it is safe for concurrent use
+type EventDispatcher struct {
+	sender EventSender
+	config EventDispatcherConfig
+
+	mu     sync.Mutex
+	queue  []*Event
+	busy   int
+	closed bool
+	// space and idle are closed and replaced to wake every waiter
+	space chan struct{}
+	idle  chan struct{}
+
+	wake   chan struct{}
+	stop   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewEventDispatcher return dispatcher with its workers started
+func NewEventDispatcher(sender EventSender, config EventDispatcherConfig) *EventDispatcher {
+	if config.BufferSize <= 0 {
+		config.BufferSize = 1000
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 50
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+
+	d := &EventDispatcher{
+		sender: sender,
+		config: config,
+		space:  make(chan struct{}),
+		idle:   make(chan struct{}),
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+	}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	for i := 0; i < config.Workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+	return d
+}
+
+// Dispatch queue event, it return ErrEventDropped when event is rejected by BackpressureDropNewest
+func (d *EventDispatcher) Dispatch(ctx context.Context, event *Event) error {
+	return d.dispatch(ctx, event, true)
+}
+
+// TryDispatch queue event without waiting, BackpressureBlock behave like BackpressureDropNewest
+// so callers on a request path are never blocked by a full queue
+func (d *EventDispatcher) TryDispatch(event *Event) error {
+	return d.dispatch(context.Background(), event, false)
+}
+
+func (d *EventDispatcher) dispatch(ctx context.Context, event *Event, block bool) error {
+	d.mu.Lock()
+	for {
+		if d.closed {
+			d.mu.Unlock()
+			return ErrDispatcherClosed
+		}
+		if len(d.queue) < d.config.BufferSize {
+			d.queue = append(d.queue, event)
+			full := len(d.queue) >= d.config.BatchSize
+			d.mu.Unlock()
+			if full {
+				d.notify()
+			}
+			return nil
+		}
+
+		backpressure := d.config.Backpressure
+		if !block && backpressure == BackpressureBlock {
+			backpressure = BackpressureDropNewest
+		}
+		switch backpressure {
+		case BackpressureDropNewest:
+			d.mu.Unlock()
+			d.deadLetter(event, ErrEventDropped)
+			return ErrEventDropped
+		case BackpressureDropOldest:
+			oldest := d.queue[0]
+			d.queue = append(d.queue[1:], event)
+			d.mu.Unlock()
+			d.deadLetter(oldest, ErrEventDropped)
+			return nil
+		}
+
+		space := d.space
+		d.mu.Unlock()
+		select {
+		case <-space:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		d.mu.Lock()
+	}
+}
+
+// Len return number of queued events, events being sent are not counted
+func (d *EventDispatcher) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.queue)
+}
+
+// Flush send every queued event and wait until events taken by workers are sent
+func (d *EventDispatcher) Flush(ctx context.Context) error {
+	d.drain(ctx)
+	for {
+		d.mu.Lock()
+		if d.busy == 0 {
+			d.mu.Unlock()
+			return nil
+		}
+		idle := d.idle
+		d.mu.Unlock()
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Close stop accepting events then drain the queue, events still queued when ctx is done
+// are given to OnDeadLetter and in-flight requests are cancelled
+func (d *EventDispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return ErrDispatcherClosed
+	}
+	d.closed = true
+	d.signal(&d.space)
+	d.mu.Unlock()
+	close(d.stop)
+
+	err := d.Flush(ctx)
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	if err == nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	if err != nil {
+		d.cancel()
+		<-done
+		d.mu.Lock()
+		left := d.queue
+		d.queue = nil
+		d.mu.Unlock()
+		for _, event := range left {
+			d.deadLetter(event, err)
+		}
+		return err
+	}
+	d.cancel()
+	return nil
+}
+
+func (d *EventDispatcher) work() {
+	defer d.wg.Done()
+	ticker := time.NewTicker(d.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.wake:
+		case <-ticker.C:
+		case <-d.stop:
+			return
+		}
+		d.drain(d.ctx)
+	}
+}
+
+// drain send batches until queue is empty
+func (d *EventDispatcher) drain(ctx context.Context) {
+	for {
+		batch := d.take(d.config.BatchSize)
+		if len(batch) == 0 {
+			return
+		}
+		for _, event := range batch {
+			if err := d.send(ctx, event); err != nil {
+				d.deadLetter(event, err)
+			}
+		}
+		d.mu.Lock()
+		d.busy--
+		d.signal(&d.idle)
+		d.mu.Unlock()
+	}
+}
+
+// take remove up to n events from queue, non empty batch must be marked done by decrementing busy
+func (d *EventDispatcher) take(n int) []*Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if n > len(d.queue) {
+		n = len(d.queue)
+	}
+	if n == 0 {
+		return nil
+	}
+	batch := append([]*Event{}, d.queue[:n]...)
+	d.queue = d.queue[n:]
+	d.busy++
+	d.signal(&d.space)
+	return batch
+}
+
+// send event, retrying according to retryPolicy
+func (d *EventDispatcher) send(ctx context.Context, event *Event) error {
+	policy := d.retryPolicy(event)
+	for attempt := 1; ; attempt++ {
+		_, _, err := d.sender.SendEventTransactionCtx(ctx, event.ContactID, event.CampaignID, event.TransactionID, event.Tracker)
+		if err == nil {
+			return nil
+		}
+		if policy == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil || !IsRetryable(err) {
+			return err
+		}
+		wait := policy.backoff(attempt, nil)
+		if e, ok := AsAPIError(err); ok && e.RetryAfter > 0 {
+			if limit := policy.retryAfterLimit(); limit > 0 && e.RetryAfter > limit {
+				return err
+			}
+			wait = e.RetryAfter
+		}
+		if sleepCtx(ctx, wait) != nil {
+			return err
+		}
+	}
+}
+
+// retryPolicy return nil for event without transaction id, PAM may have recorded it before
+// the response was lost so sending it again would duplicate it. It also return nil when
+// sender retry events by itself so attempts are not multiplied
+func (d *EventDispatcher) retryPolicy(event *Event) *RetryPolicy {
+	if len(event.TransactionID) == 0 {
+		return nil
+	}
+	if s, ok := d.sender.(retryingSender); ok && s.retriesEvents() {
+		return nil
+	}
+	return d.config.Retry
+}
+
+func (d *EventDispatcher) deadLetter(event *Event, err error) {
+	if d.config.OnDeadLetter != nil {
+		d.config.OnDeadLetter(event, err)
+	}
+}
+
+func (d *EventDispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// signal wake every goroutine waiting on ch, it must be called with mu held
+func (d *EventDispatcher) signal(ch *chan struct{}) {
+	close(*ch)
+	*ch = make(chan struct{})
+}
@@ -0,0 +1,308 @@
+package pam4sdk
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type DispatcherTestSuite struct {
+	suite.Suite
+}
+
+func TestDispatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(DispatcherTestSuite))
+}
+
+// fakeSender record sent events, fn decide result of each call
+type fakeSender struct {
+	mu   sync.Mutex
+	sent []string
+	fn   func(event string) error
+}
+
+func (s *fakeSender) SendEventTransactionCtx(ctx context.Context, contactID string, campaignID string, transactionID string, tracker *Tracker) (*EventResult, string, error) {
+	if s.fn != nil {
+		if err := s.fn(tracker.Event); err != nil {
+			return nil, "", err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, tracker.Event)
+	return &EventResult{ContactID: contactID}, "", nil
+}
+
+func (s *fakeSender) events() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.sent...)
+}
+
+func event(name string) *Event {
+	return &Event{ContactID: "contact_123", Tracker: &Tracker{Event: name}}
+}
+
+// txEvent return event having transaction id
+func txEvent(name string) *Event {
+	e := event(name)
+	e.TransactionID = "tx_" + name
+	return e
+}
+
+func (ts *DispatcherTestSuite) TestDispatch_GivenBatchSizeReached_ExpectFlushedWithoutWaitingInterval() {
+	is := assert.New(ts.T())
+	sent := make(chan string, 2)
+	sender := &fakeSender{fn: func(e string) error {
+		sent <- e
+		return nil
+	}}
+	d := NewEventDispatcher(sender, EventDispatcherConfig{BatchSize: 2, FlushInterval: time.Hour})
+	defer d.Close(context.Background())
+
+	is.NoError(d.Dispatch(context.Background(), event("e1")))
+	is.NoError(d.Dispatch(context.Background(), event("e2")))
+	for _, expected := range []string{"e1", "e2"} {
+		select {
+		case e := <-sent:
+			is.Equal(expected, e)
+		case <-time.After(time.Second):
+			is.Fail("event was not flushed")
+		}
+	}
+}
+
+func (ts *DispatcherTestSuite) TestDispatch_GivenFlushInterval_ExpectFlushedByTimer() {
+	is := assert.New(ts.T())
+	sent := make(chan string, 1)
+	sender := &fakeSender{fn: func(e string) error {
+		sent <- e
+		return nil
+	}}
+	d := NewEventDispatcher(sender, EventDispatcherConfig{BatchSize: 100, FlushInterval: 10 * time.Millisecond})
+	defer d.Close(context.Background())
+
+	is.NoError(d.Dispatch(context.Background(), event("e1")))
+	select {
+	case e := <-sent:
+		is.Equal("e1", e)
+	case <-time.After(time.Second):
+		is.Fail("event was not flushed")
+	}
+}
+
+// blockedDispatcher return dispatcher whose only worker is blocked sending e1 until release is closed
+func (ts *DispatcherTestSuite) blockedDispatcher(policy Backpressure, dead *[]string) (*EventDispatcher, chan struct{}) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	sender := &fakeSender{fn: func(e string) error {
+		if e == "e1" {
+			close(started)
+			<-release
+		}
+		return nil
+	}}
+	d := NewEventDispatcher(sender, EventDispatcherConfig{
+		BufferSize:    1,
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+		Backpressure:  policy,
+		OnDeadLetter: func(event *Event, err error) {
+			ts.Equal(ErrEventDropped, err)
+			*dead = append(*dead, event.Tracker.Event)
+		},
+	})
+	d.Dispatch(context.Background(), event("e1"))
+	<-started
+	return d, release
+}
+
+func (ts *DispatcherTestSuite) TestDispatch_GivenDropNewestAndFullQueue_ExpectNewEventDropped() {
+	is := assert.New(ts.T())
+	var dead []string
+	d, release := ts.blockedDispatcher(BackpressureDropNewest, &dead)
+
+	is.NoError(d.Dispatch(context.Background(), event("e2")))
+	is.Equal(ErrEventDropped, d.Dispatch(context.Background(), event("e3")))
+	is.Equal([]string{"e3"}, dead)
+	is.Equal(1, d.Len())
+
+	close(release)
+	is.NoError(d.Close(context.Background()))
+}
+
+func (ts *DispatcherTestSuite) TestDispatch_GivenDropOldestAndFullQueue_ExpectOldestEventDropped() {
+	is := assert.New(ts.T())
+	var dead []string
+	d, release := ts.blockedDispatcher(BackpressureDropOldest, &dead)
+
+	is.NoError(d.Dispatch(context.Background(), event("e2")))
+	is.NoError(d.Dispatch(context.Background(), event("e3")))
+	is.Equal([]string{"e2"}, dead)
+
+	close(release)
+	is.NoError(d.Close(context.Background()))
+}
+
+func (ts *DispatcherTestSuite) TestDispatch_GivenBlockAndFullQueue_ExpectWaitUntilContextDone() {
+	is := assert.New(ts.T())
+	var dead []string
+	d, release := ts.blockedDispatcher(BackpressureBlock, &dead)
+
+	is.NoError(d.Dispatch(context.Background(), event("e2")))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	is.Equal(context.DeadlineExceeded, d.Dispatch(ctx, event("e3")))
+	is.Empty(dead)
+
+	close(release)
+	is.NoError(d.Close(context.Background()))
+}
+
+func (ts *DispatcherTestSuite) TestTryDispatch_GivenBlockAndFullQueue_ExpectDroppedWithoutWaiting() {
+	is := assert.New(ts.T())
+	var dead []string
+	d, release := ts.blockedDispatcher(BackpressureBlock, &dead)
+
+	is.NoError(d.TryDispatch(event("e2")))
+	is.Equal(ErrEventDropped, d.TryDispatch(event("e3")))
+	is.Equal([]string{"e3"}, dead)
+
+	close(release)
+	is.NoError(d.Close(context.Background()))
+}
+
+func (ts *DispatcherTestSuite) TestFlush_GivenRetryableAndPermanentErrors_ExpectRetriedThenDeadLettered() {
+	is := assert.New(ts.T())
+	calls := map[string]int{}
+	sender := &fakeSender{fn: func(e string) error {
+		calls[e]++
+		if e == "retry" && calls[e] < 3 {
+			return &APIError{StatusCode: http.StatusServiceUnavailable}
+		}
+		if e == "bad" {
+			return &APIError{StatusCode: http.StatusBadRequest}
+		}
+		return nil
+	}}
+	var dead []string
+	d := NewEventDispatcher(sender, EventDispatcherConfig{
+		BatchSize:     100,
+		FlushInterval: time.Hour,
+		Retry:         &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+		OnDeadLetter: func(event *Event, err error) {
+			dead = append(dead, event.Tracker.Event)
+		},
+	})
+	defer d.Close(context.Background())
+
+	is.NoError(d.Dispatch(context.Background(), txEvent("retry")))
+	is.NoError(d.Dispatch(context.Background(), txEvent("bad")))
+	is.NoError(d.Flush(context.Background()))
+
+	is.Equal([]string{"retry"}, sender.events())
+	is.Equal(3, calls["retry"])
+	is.Equal(1, calls["bad"])
+	is.Equal([]string{"bad"}, dead)
+}
+
+// retryingFakeSender is fakeSender which retry events by itself
+type retryingFakeSender struct {
+	fakeSender
+}
+
+func (s *retryingFakeSender) retriesEvents() bool {
+	return true
+}
+
+func (ts *DispatcherTestSuite) TestFlush_GivenEventNotSafeToRetry_ExpectSentOnce() {
+	is := assert.New(ts.T())
+	calls := map[string]int{}
+	fail := func(e string) error {
+		calls[e]++
+		if e == "long-wait" {
+			return &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour}
+		}
+		return &APIError{StatusCode: http.StatusServiceUnavailable}
+	}
+	var dead []string
+	config := EventDispatcherConfig{
+		BatchSize:     100,
+		FlushInterval: time.Hour,
+		Retry:         &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxRetryAfter: time.Second},
+		OnDeadLetter: func(event *Event, err error) {
+			dead = append(dead, event.Tracker.Event)
+		},
+	}
+	d := NewEventDispatcher(&fakeSender{fn: fail}, config)
+	is.NoError(d.Dispatch(context.Background(), event("no-transaction")))
+	is.NoError(d.Dispatch(context.Background(), txEvent("long-wait")))
+	is.NoError(d.Close(context.Background()))
+
+	retrying := &retryingFakeSender{fakeSender{fn: fail}}
+	d = NewEventDispatcher(retrying, config)
+	is.NoError(d.Dispatch(context.Background(), txEvent("sender-retried")))
+	is.NoError(d.Close(context.Background()))
+
+	is.Equal(map[string]int{"no-transaction": 1, "long-wait": 1, "sender-retried": 1}, calls)
+	is.Equal([]string{"no-transaction", "long-wait", "sender-retried"}, dead)
+}
+
+func (ts *DispatcherTestSuite) TestRetriesEvents_GivenSdkWithRetryPolicy_ExpectTrue() {
+	is := assert.New(ts.T())
+	connector := &SDKConnector{BaseURL: "http://localhost", AppID: "app-id", AppSecret: "secret"}
+
+	is.True(NewClient(WithConnect(connector), WithRetryPolicy(DefaultRetryPolicy())).retriesEvents())
+	is.False(NewClient(WithConnect(connector)).retriesEvents())
+}
+
+func (ts *DispatcherTestSuite) TestFlush_GivenOpenBreakerOrCancelledError_ExpectDeadLetteredWithoutRetryButTimeoutRetried() {
+	is := assert.New(ts.T())
+	calls := map[string]int{}
+	sender := &fakeSender{fn: func(e string) error {
+		calls[e]++
+		switch e {
+		case "open":
+			return NewErr(ErrCircuitOpen)
+		case "cancelled":
+			return NewErr(context.Canceled)
+		}
+		return context.DeadlineExceeded
+	}}
+	var dead []string
+	d := NewEventDispatcher(sender, EventDispatcherConfig{
+		BatchSize:     100,
+		FlushInterval: time.Hour,
+		Retry:         &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+		OnDeadLetter: func(event *Event, err error) {
+			dead = append(dead, event.Tracker.Event)
+		},
+	})
+	defer d.Close(context.Background())
+
+	for _, name := range []string{"open", "cancelled", "expired"} {
+		is.NoError(d.Dispatch(context.Background(), txEvent(name)))
+	}
+	is.NoError(d.Flush(context.Background()))
+
+	is.Equal(map[string]int{"open": 1, "cancelled": 1, "expired": 3}, calls)
+	is.Equal([]string{"open", "cancelled", "expired"}, dead)
+}
+
+func (ts *DispatcherTestSuite) TestClose_GivenQueuedEvents_ExpectDrainedAndDispatchRejected() {
+	is := assert.New(ts.T())
+	sender := &fakeSender{}
+	d := NewEventDispatcher(sender, EventDispatcherConfig{BatchSize: 100, FlushInterval: time.Hour, Workers: 2})
+
+	for _, name := range []string{"e1", "e2", "e3"} {
+		is.NoError(d.Dispatch(context.Background(), event(name)))
+	}
+	is.NoError(d.Close(context.Background()))
+	is.Equal([]string{"e1", "e2", "e3"}, sender.events())
+	is.Equal(ErrDispatcherClosed, d.Dispatch(context.Background(), event("e4")))
+}
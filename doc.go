@@ -0,0 +1,5 @@
+// Package pam4sdk is client of PAM APIs.
+//
+// The package requires Go 1.18 or later since Iterator and Page are generic,
+// dependencies are managed by glide, see glide.yaml.
+package pam4sdk
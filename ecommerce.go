@@ -0,0 +1,319 @@
+package pam4sdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// E-commerce event names
+const (
+	EventProductView = "product_view"
+	EventAddToCart   = "add_to_cart"
+	EventCheckout    = "checkout"
+	EventPurchase    = "purchase"
+)
+
+// CommerceEvent is typed e-commerce event sent by SendCommerceEvent
+type CommerceEvent interface {
+	// Validate return ValidationError when a required field is missing or invalid
+	Validate() error
+	// Tracker return copy of base with event name and form fields of the event, base may be nil
+	Tracker(base *Tracker) *Tracker
+	// TransactionID return id which make the event safe to resend, empty when the event may repeat
+	TransactionID() string
+}
+
+// LineItem is product in cart or order
+type LineItem struct {
+	ProductID string
+	Title     string
+	Category  string
+	Price     float64
+	Quantity  int
+}
+
+func (item *LineItem) fields() map[string]interface{} {
+	return map[string]interface{}{
+		"product_id": item.ProductID,
+		"title":      item.Title,
+		"category":   item.Category,
+		"price":      item.Price,
+		"quantity":   item.Quantity,
+	}
+}
+
+// ProductView is sent when contact open product page
+type ProductView struct {
+	ProductID string
+	Title     string
+	Category  string
+	Price     float64
+	Currency  string
+}
+
+// Validate implement CommerceEvent
+func (e *ProductView) Validate() error {
+	if len(e.ProductID) == 0 {
+		return &ValidationError{Event: EventProductView, Field: "ProductID", Reason: "is required"}
+	}
+	if e.Price < 0 {
+		return &ValidationError{Event: EventProductView, Field: "Price", Reason: "must not be negative"}
+	}
+	return validateCurrency(EventProductView, e.Currency, false)
+}
+
+// Tracker implement CommerceEvent
+func (e *ProductView) Tracker(base *Tracker) *Tracker {
+	return commerceTracker(base, EventProductView, map[string]interface{}{
+		"product_id":       e.ProductID,
+		"product_title":    e.Title,
+		"product_category": e.Category,
+		"product_price":    e.Price,
+		"currency":         e.Currency,
+	})
+}
+
+// TransactionID implement CommerceEvent
+func (e *ProductView) TransactionID() string {
+	return ""
+}
+
+// AddToCart is sent when contact add product to cart
+type AddToCart struct {
+	Item     LineItem
+	Currency string
+	// CartID identify the cart, optional
+	CartID string
+}
+
+// Validate implement CommerceEvent
+func (e *AddToCart) Validate() error {
+	if err := validateItem(EventAddToCart, "Item", &e.Item); err != nil {
+		return err
+	}
+	return validateCurrency(EventAddToCart, e.Currency, false)
+}
+
+// Tracker implement CommerceEvent
+func (e *AddToCart) Tracker(base *Tracker) *Tracker {
+	return commerceTracker(base, EventAddToCart, map[string]interface{}{
+		"product_id":       e.Item.ProductID,
+		"product_title":    e.Item.Title,
+		"product_category": e.Item.Category,
+		"product_price":    e.Item.Price,
+		"quantity":         e.Item.Quantity,
+		"currency":         e.Currency,
+		"cart_id":          e.CartID,
+	})
+}
+
+// TransactionID implement CommerceEvent
+func (e *AddToCart) TransactionID() string {
+	return ""
+}
+
+// Checkout is sent when contact start checkout
+type Checkout struct {
+	CartID   string
+	Items    []LineItem
+	Currency string
+	// Total default to sum of items
+	Total float64
+}
+
+// Validate implement CommerceEvent
+func (e *Checkout) Validate() error {
+	if err := validateItems(EventCheckout, e.Items); err != nil {
+		return err
+	}
+	if e.Total < 0 {
+		return &ValidationError{Event: EventCheckout, Field: "Total", Reason: "must not be negative"}
+	}
+	return validateCurrency(EventCheckout, e.Currency, true)
+}
+
+// Tracker implement CommerceEvent
+func (e *Checkout) Tracker(base *Tracker) *Tracker {
+	total := e.Total
+	if total == 0 {
+		total = itemsTotal(e.Items)
+	}
+	return commerceTracker(base, EventCheckout, map[string]interface{}{
+		"cart_id":  e.CartID,
+		"items":    itemsFields(e.Items),
+		"currency": e.Currency,
+		"total":    total,
+	})
+}
+
+// TransactionID implement CommerceEvent
+func (e *Checkout) TransactionID() string {
+	return ""
+}
+
+// Purchase is sent when order is placed, order id is sent as _transaction_id so PAM count it once
+type Purchase struct {
+	OrderID  string
+	Items    []LineItem
+	Currency string
+	// Subtotal default to sum of items
+	Subtotal float64
+	Shipping float64
+	Tax      float64
+	Discount float64
+	// Total default to Subtotal + Shipping + Tax - Discount
+	Total float64
+}
+
+// Validate implement CommerceEvent
+func (e *Purchase) Validate() error {
+	if len(e.OrderID) == 0 {
+		return &ValidationError{Event: EventPurchase, Field: "OrderID", Reason: "is required"}
+	}
+	if err := validateItems(EventPurchase, e.Items); err != nil {
+		return err
+	}
+	amounts := []struct {
+		field string
+		value float64
+	}{
+		{"Subtotal", e.Subtotal},
+		{"Shipping", e.Shipping},
+		{"Tax", e.Tax},
+		{"Discount", e.Discount},
+		{"Total", e.Total},
+	}
+	for _, amount := range amounts {
+		if amount.value < 0 {
+			return &ValidationError{Event: EventPurchase, Field: amount.field, Reason: "must not be negative"}
+		}
+	}
+	if _, total := e.totals(); total < 0 {
+		return &ValidationError{Event: EventPurchase, Field: "Discount", Reason: "must not exceed subtotal, shipping and tax"}
+	}
+	return validateCurrency(EventPurchase, e.Currency, true)
+}
+
+// Tracker implement CommerceEvent
+func (e *Purchase) Tracker(base *Tracker) *Tracker {
+	subtotal, total := e.totals()
+	return commerceTracker(base, EventPurchase, map[string]interface{}{
+		"order_id": e.OrderID,
+		"items":    itemsFields(e.Items),
+		"currency": e.Currency,
+		"subtotal": subtotal,
+		"shipping": e.Shipping,
+		"tax":      e.Tax,
+		"discount": e.Discount,
+		"total":    total,
+	})
+}
+
+// TransactionID implement CommerceEvent
+func (e *Purchase) TransactionID() string {
+	return e.OrderID
+}
+
+func (e *Purchase) totals() (float64, float64) {
+	subtotal := e.Subtotal
+	if subtotal == 0 {
+		subtotal = itemsTotal(e.Items)
+	}
+	total := e.Total
+	if total == 0 {
+		total = subtotal + e.Shipping + e.Tax - e.Discount
+	}
+	return subtotal, total
+}
+
+// SendCommerceEvent validate event then post it to PAM, base carry page and visitor fields and may be nil
+func (sdk *Sdk) SendCommerceEvent(contactID string, event CommerceEvent, base *Tracker) (string, error) {
+	_, raw, err := sdk.SendCommerceEventCtx(context.Background(), contactID, event, base)
+	return raw, err
+}
+
+// SendCommerceEventCtx validate event then post it to PAM with context, event with transaction id
+// such as Purchase is sent by SendEventTransactionCtx
+func (sdk *Sdk) SendCommerceEventCtx(ctx context.Context, contactID string, event CommerceEvent, base *Tracker) (*EventResult, string, error) {
+	if err := event.Validate(); err != nil {
+		return nil, "", err
+	}
+	return sdk.SendEventTransactionCtx(ctx, contactID, "", event.TransactionID(), event.Tracker(base))
+}
+
+// commerceTracker return copy of base with event and fields added to its form fields
+func commerceTracker(base *Tracker, event string, fields map[string]interface{}) *Tracker {
+	t := Tracker{}
+	if base != nil {
+		t = *base
+	}
+	t.Event = event
+	formFields := make(map[string]interface{}, len(t.FormFields)+len(fields))
+	for key, value := range t.FormFields {
+		formFields[key] = value
+	}
+	for key, value := range fields {
+		if s, ok := value.(string); ok && len(s) == 0 {
+			continue
+		}
+		formFields[key] = value
+	}
+	t.FormFields = formFields
+	return &t
+}
+
+func validateItem(event string, field string, item *LineItem) error {
+	if len(item.ProductID) == 0 {
+		return &ValidationError{Event: event, Field: field + ".ProductID", Reason: "is required"}
+	}
+	if item.Quantity <= 0 {
+		return &ValidationError{Event: event, Field: field + ".Quantity", Reason: "must be positive"}
+	}
+	if item.Price < 0 {
+		return &ValidationError{Event: event, Field: field + ".Price", Reason: "must not be negative"}
+	}
+	return nil
+}
+
+func validateItems(event string, items []LineItem) error {
+	if len(items) == 0 {
+		return &ValidationError{Event: event, Field: "Items", Reason: "must not be empty"}
+	}
+	for i := range items {
+		if err := validateItem(event, fmt.Sprintf("Items[%d]", i), &items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCurrency check currency is ISO 4217 code such as THB
+func validateCurrency(event string, currency string, required bool) error {
+	if len(currency) == 0 {
+		if required {
+			return &ValidationError{Event: event, Field: "Currency", Reason: "is required"}
+		}
+		return nil
+	}
+	if len(currency) != 3 || strings.Trim(currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return &ValidationError{Event: event, Field: "Currency", Reason: "must be ISO 4217 code"}
+	}
+	return nil
+}
+
+func itemsFields(items []LineItem) []map[string]interface{} {
+	fields := make([]map[string]interface{}, 0, len(items))
+	for i := range items {
+		fields = append(fields, items[i].fields())
+	}
+	return fields
+}
+
+func itemsTotal(items []LineItem) float64 {
+	total := 0.0
+	for _, item := range items {
+		total += item.Price * float64(item.Quantity)
+	}
+	return total
+}
@@ -0,0 +1,120 @@
+package pam4sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/mock"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type EcommerceTestSuite struct {
+	suite.Suite
+}
+
+func TestEcommerceTestSuite(t *testing.T) {
+	suite.Run(t, new(EcommerceTestSuite))
+}
+
+func (ts *EcommerceTestSuite) purchase() *Purchase {
+	return &Purchase{
+		OrderID: "order_1",
+		Items: []LineItem{
+			{ProductID: "p1", Title: "Shirt", Price: 250, Quantity: 2},
+			{ProductID: "p2", Title: "Hat", Price: 100, Quantity: 1},
+		},
+		Currency: "THB",
+		Shipping: 50,
+		Discount: 100,
+	}
+}
+
+func (ts *EcommerceTestSuite) TestPurchaseTracker_GivenBaseTracker_ExpectFormFieldsAndTotalsWithoutModifyingBase() {
+	is := assert.New(ts.T())
+	base := &Tracker{PageURL: "https://shop.example.com/thanks", FormFields: map[string]interface{}{"source": "web"}}
+
+	tracker := ts.purchase().Tracker(base)
+
+	is.Equal(EventPurchase, tracker.Event)
+	is.Equal("https://shop.example.com/thanks", tracker.PageURL)
+	is.Equal("web", tracker.FormFields["source"])
+	is.Equal("order_1", tracker.FormFields["order_id"])
+	is.Equal("THB", tracker.FormFields["currency"])
+	is.Equal(600.0, tracker.FormFields["subtotal"])
+	is.Equal(550.0, tracker.FormFields["total"])
+	if items, ok := tracker.FormFields["items"].([]map[string]interface{}); is.True(ok) && is.Len(items, 2) {
+		is.Equal("p1", items[0]["product_id"])
+		is.Equal(2, items[0]["quantity"])
+	}
+	is.Equal("", base.Event)
+	is.Len(base.FormFields, 1)
+}
+
+func (ts *EcommerceTestSuite) TestValidate_GivenInvalidEvents_ExpectValidationErrorOfField() {
+	is := assert.New(ts.T())
+	noOrder := ts.purchase()
+	noOrder.OrderID = ""
+	badItem := ts.purchase()
+	badItem.Items[1].Quantity = 0
+	badCurrency := ts.purchase()
+	badCurrency.Currency = "baht"
+	bigDiscount := ts.purchase()
+	bigDiscount.Discount = 1000
+
+	cases := []struct {
+		event CommerceEvent
+		field string
+	}{
+		{&ProductView{}, "ProductID"},
+		{&ProductView{ProductID: "p1", Currency: "TH"}, "Currency"},
+		{&AddToCart{Item: LineItem{ProductID: "p1"}}, "Item.Quantity"},
+		{&Checkout{Currency: "THB"}, "Items"},
+		{&Checkout{Items: []LineItem{{ProductID: "p1", Quantity: 1}}}, "Currency"},
+		{noOrder, "OrderID"},
+		{badItem, "Items[1].Quantity"},
+		{badCurrency, "Currency"},
+		{bigDiscount, "Discount"},
+	}
+	for _, c := range cases {
+		err := c.event.Validate()
+		var validationErr *ValidationError
+		if is.True(errors.As(err, &validationErr), c.field) {
+			is.Equal(c.field, validationErr.Field)
+		}
+	}
+	is.NoError((&ProductView{ProductID: "p1"}).Validate())
+	is.NoError(ts.purchase().Validate())
+}
+
+func (ts *EcommerceTestSuite) TestSendCommerceEventCtx_GivenPurchase_ExpectSentAsTransactionOfOrderID() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(&RequestLogger{rq: mockRq, logger: NewMockLogger()}, nil)
+
+	headers := map[string]string{IdempotencyKeyHeader: "order_1"}
+	body := mock.MatchedBy(func(p map[string]interface{}) bool {
+		fields := p["form_fields"].(map[string]interface{})
+		return p["event"] == EventPurchase && fields["_transaction_id"] == "order_1" && fields["total"] == 550.0
+	})
+	mockRq.On("PostJSONRHCCtx", mock.Anything, "/trackers/events", body, headers, mock.Anything).Return(&http.Response{}, `{"contact_id":"contact_1"}`, nil)
+
+	res, _, err := sdk.SendCommerceEventCtx(context.Background(), "contact_1", ts.purchase(), nil)
+	if is.NoError(err) {
+		is.Equal("contact_1", res.ContactID)
+		mockRq.AssertExpectations(ts.T())
+	}
+}
+
+func (ts *EcommerceTestSuite) TestSendCommerceEvent_GivenInvalidEvent_ExpectNotSent() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(&RequestLogger{rq: mockRq, logger: NewMockLogger()}, nil)
+
+	_, err := sdk.SendCommerceEvent("contact_1", &AddToCart{}, nil)
+
+	is.Error(err)
+	mockRq.AssertNotCalled(ts.T(), "PostJSONRHCCtx", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -1,7 +1,16 @@
 package pam4sdk
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"runtime"
+	"strings"
+	"time"
 )
 
 // IError is interface for error
@@ -81,3 +90,164 @@ func NewErrorMU(logger ILogger, message string) *Error {
 func (e *Error) Error() string {
 	return e.message
 }
+
+// Unwrap return the wrapped error, nil when error is created from message
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// ForUser return true when error message can be shown to user
+func (e *Error) ForUser() bool {
+	return e.forUser
+}
+
+// ConnectorNotConfiguredError is returned when calling a method whose connector is not configured
+type ConnectorNotConfiguredError struct {
+	Connector string
+}
+
+// Error return error message
+func (e *ConnectorNotConfiguredError) Error() string {
+	return fmt.Sprintf("pam %s connector is not configured", e.Connector)
+}
+
+// ValidationError is returned when a typed event miss a required field or has invalid value
+type ValidationError struct {
+	Event  string
+	Field  string
+	Reason string
+}
+
+// Error return error message
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("pam %s event: %s %s", e.Event, e.Field, e.Reason)
+}
+
+// APIError is returned when PAM respond with 4xx or 5xx status
+type APIError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	Path       string
+	Body       string
+	// Code and Message are parsed from PAM error body, empty when body is not PAM error
+	Code    string
+	Message string
+	// RetryAfter is parsed from Retry-After header, zero when header is absent
+	RetryAfter time.Duration
+}
+
+// NewAPIError return APIError for the failed response
+func NewAPIError(method string, path string, res *http.Response, body string) *APIError {
+	e := &APIError{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Method:     method,
+		Path:       path,
+		Body:       body,
+	}
+	e.Code, e.Message = parseAPIErrorBody(body)
+	e.RetryAfter, _ = parseRetryAfter(res.Header.Get("Retry-After"), time.Now())
+	return e
+}
+
+// Error return error message
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: %s", e.Method, e.Path, e.Status)
+	if len(e.Code) > 0 {
+		msg = fmt.Sprintf("%s [%s]", msg, e.Code)
+	}
+	if len(e.Message) > 0 {
+		msg = fmt.Sprintf("%s %s", msg, e.Message)
+	}
+	return msg
+}
+
+// parseAPIErrorBody return code and message from PAM error body
+func parseAPIErrorBody(body string) (string, string) {
+	p := struct {
+		Code      interface{} `json:"code"`
+		ErrorCode interface{} `json:"error_code"`
+		Message   string      `json:"message"`
+		Error     interface{} `json:"error"`
+	}{}
+	if err := json.Unmarshal([]byte(body), &p); err != nil {
+		return "", ""
+	}
+
+	code := ""
+	if p.ErrorCode != nil {
+		code = fmt.Sprintf("%v", p.ErrorCode)
+	} else if p.Code != nil {
+		code = fmt.Sprintf("%v", p.Code)
+	}
+	msg := strings.TrimSpace(p.Message)
+	if e, ok := p.Error.(string); ok && len(msg) == 0 {
+		msg = e
+	}
+	return code, msg
+}
+
+// AsAPIError return APIError in err chain
+func AsAPIError(err error) (*APIError, bool) {
+	var e *APIError
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// IsNotFound return true when PAM respond 404
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized return true when PAM reject credential with 401 or 403
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized, http.StatusForbidden)
+}
+
+// IsRateLimited return true when PAM respond 429
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+// IsRetryable return true when the same request may succeed if sent again, that is PAM respond
+// 408, 429 or 5xx, the request timed out or failed with network error. Cancelled context,
+// ErrCircuitOpen and ConnectorNotConfiguredError are not retryable
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if _, ok := AsAPIError(err); ok {
+		return hasStatus(err,
+			http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout)
+	}
+	var notConfigured *ConnectorNotConfiguredError
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrCircuitOpen) || errors.As(err, &notConfigured) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func hasStatus(err error, statuses ...int) bool {
+	e, ok := AsAPIError(err)
+	if !ok {
+		return false
+	}
+	for _, status := range statuses {
+		if e.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,17 @@
 package pam4sdk
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
 	"testing"
 
 	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/mock"
 	"github.com/3dsinteractive/testify/suite"
 )
 
@@ -56,3 +63,68 @@ func (ts *ErrorTestSuite) TestNewErrorM_GiveMessage_MustReturnNewError() {
 func TestErrorTestSuite(t *testing.T) {
 	suite.Run(t, new(ErrorTestSuite))
 }
+
+func (ts *ErrorTestSuite) TestError_GivenWrappedError_ExpectUnwrapToCause() {
+	logger := NewMockLogger()
+	logger.On("ErrorFL", context.Canceled.Error())
+
+	err := NewErrorEU(logger, context.Canceled)
+	is := assert.New(ts.T())
+	is.True(errors.Is(err, context.Canceled))
+	is.True(err.ForUser())
+	is.False(NewErrM("message").ForUser())
+}
+
+func (ts *ErrorTestSuite) TestNewAPIError_GivenPAMErrorBody_ExpectCodeAndMessageParsed() {
+	res := &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found"}
+	err := NewAPIError("GET", "/triggers/123", res, `{"code":"TRIGGER_NOT_FOUND","message":"trigger not found"}`)
+
+	is := assert.New(ts.T())
+	is.Equal("TRIGGER_NOT_FOUND", err.Code)
+	is.Equal("trigger not found", err.Message)
+	is.Equal("GET /triggers/123: 404 Not Found [TRIGGER_NOT_FOUND] trigger not found", err.Error())
+}
+
+func (ts *ErrorTestSuite) TestNewAPIError_GivenPlainBody_ExpectNoCode() {
+	res := &http.Response{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway"}
+	err := NewAPIError("POST", "/trackers/events", res, "<html>bad gateway</html>")
+
+	is := assert.New(ts.T())
+	is.Empty(err.Code)
+	is.Empty(err.Message)
+	is.Equal("<html>bad gateway</html>", err.Body)
+	is.Equal("POST /trackers/events: 502 Bad Gateway", err.Error())
+}
+
+func (ts *ErrorTestSuite) TestPredicates_GivenWrappedAPIError_ExpectMatchStatus() {
+	logger := NewMockLogger()
+	logger.On("ErrorFL", mock.Anything)
+
+	notFound := NewErrorE(logger, &APIError{StatusCode: http.StatusNotFound})
+	unauthorized := fmt.Errorf("send event: %w", &APIError{StatusCode: http.StatusUnauthorized})
+	rateLimited := &APIError{StatusCode: http.StatusTooManyRequests}
+	badRequest := &APIError{StatusCode: http.StatusBadRequest}
+
+	is := assert.New(ts.T())
+	is.True(IsNotFound(notFound))
+	is.False(IsNotFound(unauthorized))
+	is.True(IsUnauthorized(unauthorized))
+	is.True(IsRateLimited(rateLimited))
+	is.True(IsRetryable(rateLimited))
+	is.True(IsRetryable(&APIError{StatusCode: http.StatusServiceUnavailable}))
+	is.False(IsRetryable(badRequest))
+	is.False(IsRetryable(fmt.Errorf("plain error")))
+}
+
+func (ts *ErrorTestSuite) TestIsRetryable_GivenTransportErrors_ExpectNetworkAndTimeoutRetryable() {
+	is := assert.New(ts.T())
+	refused := &url.Error{Op: "Post", URL: "http://pam", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+
+	is.True(IsRetryable(NewErr(refused)))
+	is.True(IsRetryable(fmt.Errorf("send event: %w", context.DeadlineExceeded)))
+	is.True(IsRetryable(io.ErrUnexpectedEOF))
+	is.False(IsRetryable(NewErr(context.Canceled)))
+	is.False(IsRetryable(NewErr(ErrCircuitOpen)))
+	is.False(IsRetryable(&ConnectorNotConfiguredError{Connector: ConnectorConnect}))
+	is.False(IsRetryable(nil))
+}
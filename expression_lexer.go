@@ -0,0 +1,252 @@
+package pam4sdk
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Position is location in segment expression, Line and Column start at 1
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// ExpressionError is error of segment expression at Pos
+type ExpressionError struct {
+	Pos     Position
+	Message string
+}
+
+// Error return error message
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("pam segment expression %d:%d: %s", e.Pos.Line, e.Pos.Column, e.Message)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenDuration
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of expression"
+	case tokenIdent:
+		return "identifier"
+	case tokenString:
+		return "string"
+	case tokenNumber:
+		return "number"
+	case tokenDuration:
+		return "duration"
+	case tokenOperator:
+		return "operator"
+	case tokenLParen:
+		return `"("`
+	case tokenRParen:
+		return `")"`
+	case tokenComma:
+		return `","`
+	}
+	return "token"
+}
+
+type token struct {
+	kind tokenKind
+	// text is source of the token, value of string token is unquoted
+	text string
+	pos  Position
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF, tokenLParen, tokenRParen, tokenComma:
+		return t.kind.String()
+	}
+	return fmt.Sprintf("%s %q", t.kind, t.text)
+}
+
+// lexer split segment expression into tokens
+type lexer struct {
+	src  string
+	pos  Position
+	toks []token
+}
+
+// lex return tokens of src ending with tokenEOF
+func lex(src string) ([]token, error) {
+	l := &lexer{src: src, pos: Position{Line: 1, Column: 1}}
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		l.toks = append(l.toks, tok)
+		if tok.kind == tokenEOF {
+			return l.toks, nil
+		}
+	}
+}
+
+func (l *lexer) peek() (rune, int) {
+	if l.pos.Offset >= len(l.src) {
+		return 0, 0
+	}
+	return utf8.DecodeRuneInString(l.src[l.pos.Offset:])
+}
+
+func (l *lexer) advance() rune {
+	r, size := l.peek()
+	l.pos.Offset += size
+	if r == '\n' {
+		l.pos.Line++
+		l.pos.Column = 1
+	} else {
+		l.pos.Column++
+	}
+	return r
+}
+
+func (l *lexer) errorf(pos Position, format string, args ...interface{}) error {
+	return &ExpressionError{Pos: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+func (l *lexer) next() (token, error) {
+	for {
+		r, size := l.peek()
+		if size == 0 || !unicode.IsSpace(r) {
+			break
+		}
+		l.advance()
+	}
+
+	start := l.pos
+	r, size := l.peek()
+	switch {
+	case size == 0:
+		return token{kind: tokenEOF, pos: start}, nil
+	case r == '(':
+		l.advance()
+		return token{kind: tokenLParen, text: "(", pos: start}, nil
+	case r == ')':
+		l.advance()
+		return token{kind: tokenRParen, text: ")", pos: start}, nil
+	case r == ',':
+		l.advance()
+		return token{kind: tokenComma, text: ",", pos: start}, nil
+	case r == '"':
+		return l.string(start)
+	case r == '-' || isDigit(r):
+		return l.number(start)
+	case r == '=' || r == '!' || r == '<' || r == '>':
+		l.advance()
+		op := string(r)
+		if next, _ := l.peek(); next == '=' {
+			l.advance()
+			op += "="
+		}
+		if op == "!" {
+			return token{}, l.errorf(start, `unexpected "!", use "!=" or not`)
+		}
+		return token{kind: tokenOperator, text: op, pos: start}, nil
+	case isIdentStart(r):
+		for {
+			r, size := l.peek()
+			if size == 0 || !isIdentPart(r) {
+				break
+			}
+			l.advance()
+		}
+		return token{kind: tokenIdent, text: l.src[start.Offset:l.pos.Offset], pos: start}, nil
+	}
+	return token{}, l.errorf(start, "unexpected character %q", r)
+}
+
+func (l *lexer) string(start Position) (token, error) {
+	l.advance()
+	for {
+		r, size := l.peek()
+		switch {
+		case size == 0 || r == '\n':
+			return token{}, l.errorf(start, "string is not terminated")
+		case r == '\\':
+			l.advance()
+			if _, size := l.peek(); size == 0 {
+				return token{}, l.errorf(start, "string is not terminated")
+			}
+		case r == '"':
+			l.advance()
+			raw := l.src[start.Offset:l.pos.Offset]
+			value, err := strconv.Unquote(raw)
+			if err != nil {
+				return token{}, l.errorf(start, "invalid string %s", raw)
+			}
+			return token{kind: tokenString, text: value, pos: start}, nil
+		}
+		l.advance()
+	}
+}
+
+// number lex number, number directly followed by letters such as 30d is duration
+func (l *lexer) number(start Position) (token, error) {
+	if r, _ := l.peek(); r == '-' {
+		l.advance()
+		if r, _ := l.peek(); !isDigit(r) {
+			return token{}, l.errorf(start, `unexpected "-"`)
+		}
+	}
+	dot := false
+	for {
+		r, size := l.peek()
+		if size > 0 && isDigit(r) {
+			l.advance()
+			continue
+		}
+		if size > 0 && r == '.' && !dot {
+			dot = true
+			l.advance()
+			continue
+		}
+		break
+	}
+	kind := tokenNumber
+	for {
+		r, size := l.peek()
+		if size == 0 || !unicode.IsLetter(r) {
+			break
+		}
+		kind = tokenDuration
+		l.advance()
+	}
+	text := l.src[start.Offset:l.pos.Offset]
+	if strings.HasSuffix(strings.TrimRight(text, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"), ".") {
+		return token{}, l.errorf(start, "invalid number %s", text)
+	}
+	return token{kind: kind, text: text, pos: start}, nil
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// isIdentPart allow "-" and "." so attributes such as customer-id and attrs.mobile need no quoting
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || isDigit(r) || r == '-' || r == '.'
+}
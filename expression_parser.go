@@ -0,0 +1,428 @@
+package pam4sdk
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Keywords of segment expression, attributes named like a keyword are written attr("name")
+var expressionKeywords = map[string]bool{
+	"and": true, "or": true, "not": true, "in": true, "contains": true, "exists": true,
+	"true": true, "false": true, "tag": true, "event": true, "segment": true, "campaign": true,
+	"attr": true, "within": true,
+}
+
+// durationUnits map suffix of duration such as 30d to TimeUnit
+var durationUnits = map[string]TimeUnit{
+	"m":  UnitMinute,
+	"h":  UnitHour,
+	"d":  UnitDay,
+	"w":  UnitWeek,
+	"mo": UnitMonth,
+}
+
+var comparisonOperators = map[string]Operator{
+	"=":  OpEqual,
+	"==": OpEqual,
+	"!=": OpNotEqual,
+	">":  OpGreater,
+	">=": OpGreaterOrEqual,
+	"<":  OpLess,
+	"<=": OpLessOrEqual,
+}
+
+// ParseExpression parse segment expression into condition. Grammar:
+//
+//	expr      = and { "or" and }
+//	and       = unary { "and" unary }
+//	unary     = "not" unary | "(" expr ")" | predicate
+//	predicate = "tag" "in" "(" string { "," string } ")"
+//	          | "event" "(" string [ "," "within" "=" duration ] ")" compare number
+//	          | "segment" "(" string ")"
+//	          | "campaign" "(" string "," interaction ")"
+//	          | attribute ( compare value | [ "not" ] "contains" value | "in" "(" value { "," value } ")" | [ "not" ] "exists" )
+//	attribute = identifier | "attr" "(" string ")"
+//	compare   = "=" | "==" | "!=" | ">" | ">=" | "<" | "<="
+//	value     = string | number | "true" | "false"
+//	duration  = number unit where unit is m, h, d, w or mo, for example 30d
+//
+// For example: tag in ("vip") and event("purchase", within=30d) >= 2 and not segment("churned")
+func ParseExpression(src string) (Condition, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	c, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.unexpected(tok, `"and", "or" or end of expression`)
+	}
+	return c, nil
+}
+
+type parser struct {
+	toks []token
+	i    int
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *parser) next() token {
+	tok := p.toks[p.i]
+	if tok.kind != tokenEOF {
+		p.i++
+	}
+	return tok
+}
+
+// keyword return true and consume token when it is identifier word
+func (p *parser) keyword(word string) bool {
+	if tok := p.peek(); tok.kind == tokenIdent && tok.text == word {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(pos Position, format string, args ...interface{}) error {
+	return &ExpressionError{Pos: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) unexpected(tok token, expected string) error {
+	return p.errorf(tok.pos, "expected %s, found %s", expected, tok)
+}
+
+func (p *parser) expect(kind tokenKind) (token, error) {
+	tok := p.next()
+	if tok.kind != kind {
+		return tok, p.unexpected(tok, kind.String())
+	}
+	return tok, nil
+}
+
+func (p *parser) expectKeyword(word string) error {
+	if !p.keyword(word) {
+		return p.unexpected(p.peek(), fmt.Sprintf("%q", word))
+	}
+	return nil
+}
+
+func (p *parser) expr() (Condition, error) {
+	return p.group(LogicOr, p.and)
+}
+
+func (p *parser) and() (Condition, error) {
+	return p.group(LogicAnd, p.unary)
+}
+
+// group parse operands joined by logic, nested group of the same logic is flattened
+func (p *parser) group(logic Logic, operand func() (Condition, error)) (Condition, error) {
+	var conditions []Condition
+	for {
+		c, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if g, ok := c.(*GroupCondition); ok && g.Logic == logic {
+			conditions = append(conditions, g.Conditions...)
+		} else {
+			conditions = append(conditions, c)
+		}
+		if !p.keyword(string(logic)) {
+			break
+		}
+	}
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+	return &GroupCondition{Logic: logic, Conditions: conditions}, nil
+}
+
+func (p *parser) unary() (Condition, error) {
+	if p.keyword("not") {
+		c, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return Not(c), nil
+	}
+	if p.peek().kind == tokenLParen {
+		p.next()
+		c, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	return p.predicate()
+}
+
+func (p *parser) predicate() (Condition, error) {
+	start := p.peek()
+	if start.kind != tokenIdent {
+		return nil, p.unexpected(start, "condition")
+	}
+
+	var c Condition
+	var err error
+	switch start.text {
+	case "tag":
+		p.next()
+		c, err = p.tag()
+	case "event":
+		p.next()
+		c, err = p.event()
+	case "segment":
+		p.next()
+		c, err = p.segment()
+	case "campaign":
+		p.next()
+		c, err = p.campaign()
+	default:
+		c, err = p.attribute()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, p.errorf(start.pos, "%s", strings.TrimPrefix(err.Error(), "pam "))
+	}
+	return c, nil
+}
+
+// tag parse tag in ("a", "b") which match contacts having any of the tags
+func (p *parser) tag() (Condition, error) {
+	if err := p.expectKeyword("in"); err != nil {
+		return nil, err
+	}
+	values, err := p.list(func() (interface{}, error) {
+		tok, err := p.expect(tokenString)
+		return tok.text, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]Condition, 0, len(values))
+	for _, v := range values {
+		tags = append(tags, HasTag(v.(string)))
+	}
+	if len(tags) == 1 {
+		return tags[0], nil
+	}
+	return Or(tags...), nil
+}
+
+func (p *parser) event() (Condition, error) {
+	if _, err := p.expect(tokenLParen); err != nil {
+		return nil, err
+	}
+	name, err := p.expect(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	c := &EventCondition{Event: name.text}
+	if p.peek().kind == tokenComma {
+		p.next()
+		if err := p.expectKeyword("within"); err != nil {
+			return nil, err
+		}
+		if eq := p.next(); eq.kind != tokenOperator || eq.text != "=" {
+			return nil, p.unexpected(eq, `"="`)
+		}
+		d, err := p.expect(tokenDuration)
+		if err != nil {
+			return nil, err
+		}
+		if c.WithinAmount, c.WithinUnit, err = p.duration(d); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.expect(tokenRParen); err != nil {
+		return nil, err
+	}
+
+	op := p.next()
+	operator, ok := comparisonOperators[op.text]
+	if op.kind != tokenOperator || !ok {
+		return nil, p.unexpected(op, "comparison operator")
+	}
+	c.Operator = operator
+	count, err := p.expect(tokenNumber)
+	if err != nil {
+		return nil, err
+	}
+	if c.Count, err = strconv.Atoi(count.text); err != nil {
+		return nil, p.errorf(count.pos, "event count %s must be integer", count.text)
+	}
+	return c, nil
+}
+
+func (p *parser) duration(tok token) (int, TimeUnit, error) {
+	i := strings.IndexFunc(tok.text, func(r rune) bool { return !isDigit(r) })
+	amount, err := strconv.Atoi(tok.text[:i])
+	if err != nil || amount <= 0 {
+		return 0, "", p.errorf(tok.pos, "duration %s must be positive integer", tok.text)
+	}
+	unit, ok := durationUnits[tok.text[i:]]
+	if !ok {
+		return 0, "", p.errorf(tok.pos, "duration unit of %s must be m, h, d, w or mo", tok.text)
+	}
+	return amount, unit, nil
+}
+
+func (p *parser) segment() (Condition, error) {
+	if _, err := p.expect(tokenLParen); err != nil {
+		return nil, err
+	}
+	name, err := p.expect(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenRParen); err != nil {
+		return nil, err
+	}
+	return InSegment(name.text), nil
+}
+
+func (p *parser) campaign() (Condition, error) {
+	if _, err := p.expect(tokenLParen); err != nil {
+		return nil, err
+	}
+	id, err := p.expect(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenComma); err != nil {
+		return nil, err
+	}
+	interaction, err := p.expect(tokenIdent)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenRParen); err != nil {
+		return nil, err
+	}
+	return CampaignInteraction(id.text, Interaction(interaction.text)), nil
+}
+
+func (p *parser) attribute() (Condition, error) {
+	name := p.next()
+	attribute := name.text
+	if name.text == "attr" {
+		if _, err := p.expect(tokenLParen); err != nil {
+			return nil, err
+		}
+		tok, err := p.expect(tokenString)
+		if err != nil {
+			return nil, err
+		}
+		attribute = tok.text
+		if _, err := p.expect(tokenRParen); err != nil {
+			return nil, err
+		}
+	} else if expressionKeywords[name.text] {
+		return nil, p.unexpected(name, "condition")
+	}
+
+	op := p.next()
+	switch {
+	case op.kind == tokenOperator && comparisonOperators[op.text] != "":
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		return Attr(attribute, comparisonOperators[op.text], value), nil
+	case op.kind == tokenIdent && op.text == "contains":
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		return Attr(attribute, OpContains, value), nil
+	case op.kind == tokenIdent && op.text == "exists":
+		return Attr(attribute, OpExists, nil), nil
+	case op.kind == tokenIdent && op.text == "in":
+		values, err := p.list(p.value)
+		if err != nil {
+			return nil, err
+		}
+		return Attr(attribute, OpIn, values), nil
+	case op.kind == tokenIdent && op.text == "not":
+		switch {
+		case p.keyword("contains"):
+			value, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			return Attr(attribute, OpNotContains, value), nil
+		case p.keyword("exists"):
+			return Attr(attribute, OpNotExists, nil), nil
+		}
+		return nil, p.unexpected(p.peek(), `"contains" or "exists"`)
+	}
+	return nil, p.unexpected(op, "operator of "+attribute)
+}
+
+// list parse "(" item { "," item } ")"
+func (p *parser) list(item func() (interface{}, error)) ([]interface{}, error) {
+	if _, err := p.expect(tokenLParen); err != nil {
+		return nil, err
+	}
+	var values []interface{}
+	for {
+		v, err := item()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		tok := p.next()
+		if tok.kind == tokenRParen {
+			return values, nil
+		}
+		if tok.kind != tokenComma {
+			return nil, p.unexpected(tok, `"," or ")"`)
+		}
+	}
+}
+
+func (p *parser) value() (interface{}, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenString:
+		return tok.text, nil
+	case tokenNumber:
+		if n, err := strconv.Atoi(tok.text); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf(tok.pos, "invalid number %s", tok.text)
+		}
+		return f, nil
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return nil, p.unexpected(tok, "value")
+}
+
+// SetExpression replace triggers of segment with condition written in segment expression
+func (s *Segment) SetExpression(src string) error {
+	c, err := ParseExpression(src)
+	if err != nil {
+		return err
+	}
+	return s.SetCondition(c)
+}
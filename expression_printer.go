@@ -0,0 +1,230 @@
+package pam4sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// binding strength of printed condition, operand binding looser than its context is parenthesised
+const (
+	bindOr = iota + 1
+	bindAnd
+	bindUnary
+)
+
+var operatorSymbols = map[Operator]string{
+	OpEqual:          "=",
+	OpNotEqual:       "!=",
+	OpGreater:        ">",
+	OpGreaterOrEqual: ">=",
+	OpLess:           "<",
+	OpLessOrEqual:    "<=",
+}
+
+var unitSuffixes = map[TimeUnit]string{
+	UnitMinute: "m",
+	UnitHour:   "h",
+	UnitDay:    "d",
+	UnitWeek:   "w",
+	UnitMonth:  "mo",
+}
+
+// FormatCondition write condition in segment expression, ParseExpression of the result return
+// equivalent condition. Consecutive tags of Or are written as one tag in (...) predicate
+func FormatCondition(condition Condition) (string, error) {
+	if condition == nil {
+		return "", conditionError(ConditionGroup, "condition is required")
+	}
+	if err := condition.Validate(); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := writeCondition(&b, condition, bindOr); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Expression return triggers of segment written in segment expression, it explain segments
+// returned by GetSegmentByIDCtx in human readable form. Triggers which the expression cannot
+// write, such as RawCondition, are returned as JSON
+func (s *Segment) Expression() (string, error) {
+	c, err := s.Condition()
+	if err != nil {
+		return "", err
+	}
+	if expression, err := FormatCondition(c); err == nil {
+		return expression, nil
+	}
+	js, err := json.Marshal(s.Triggers)
+	if err != nil {
+		return "", NewErr(err)
+	}
+	return string(js), nil
+}
+
+func writeCondition(b *strings.Builder, condition Condition, bind int) error {
+	switch c := condition.(type) {
+	case *GroupCondition:
+		return writeGroup(b, c, bind)
+	case *NotCondition:
+		b.WriteString("not ")
+		return writeCondition(b, c.Condition, bindUnary)
+	case *TagCondition:
+		if !c.Has {
+			b.WriteString("not ")
+		}
+		writeTags(b, []string{c.Tag})
+	case *SegmentCondition:
+		if !c.In {
+			b.WriteString("not ")
+		}
+		fmt.Fprintf(b, "segment(%s)", strconv.Quote(c.Segment))
+	case *CampaignCondition:
+		if !c.Did {
+			b.WriteString("not ")
+		}
+		fmt.Fprintf(b, "campaign(%s, %s)", strconv.Quote(c.CampaignID), c.Interaction)
+	case *EventCondition:
+		fmt.Fprintf(b, "event(%s", strconv.Quote(c.Event))
+		if c.WithinAmount > 0 {
+			fmt.Fprintf(b, ", within=%d%s", c.WithinAmount, unitSuffixes[c.WithinUnit])
+		}
+		fmt.Fprintf(b, ") %s %d", operatorSymbols[c.Operator], c.Count)
+	case *AttributeCondition:
+		return writeAttribute(b, c)
+	default:
+		return conditionError("unknown", fmt.Sprintf("%T cannot be written as expression", condition))
+	}
+	return nil
+}
+
+func writeGroup(b *strings.Builder, g *GroupCondition, bind int) error {
+	if len(g.Conditions) == 1 {
+		return writeCondition(b, g.Conditions[0], bind)
+	}
+	own := bindAnd
+	if g.Logic == LogicOr {
+		own = bindOr
+	}
+	if own < bind {
+		b.WriteString("(")
+		defer b.WriteString(")")
+	}
+	for i := 0; i < len(g.Conditions); i++ {
+		if i > 0 {
+			fmt.Fprintf(b, " %s ", g.Logic)
+		}
+		if g.Logic == LogicOr {
+			if tags := hasTags(g.Conditions[i:]); len(tags) > 1 {
+				writeTags(b, tags)
+				i += len(tags) - 1
+				continue
+			}
+		}
+		if err := writeCondition(b, g.Conditions[i], own+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasTags return tags of leading HasTag conditions
+func hasTags(conditions []Condition) []string {
+	var tags []string
+	for _, c := range conditions {
+		t, ok := c.(*TagCondition)
+		if !ok || !t.Has {
+			break
+		}
+		tags = append(tags, t.Tag)
+	}
+	return tags
+}
+
+func writeTags(b *strings.Builder, tags []string) {
+	quoted := make([]string, 0, len(tags))
+	for _, t := range tags {
+		quoted = append(quoted, strconv.Quote(t))
+	}
+	fmt.Fprintf(b, "tag in (%s)", strings.Join(quoted, ", "))
+}
+
+func writeAttribute(b *strings.Builder, c *AttributeCondition) error {
+	if isBareIdent(c.Attribute) {
+		b.WriteString(c.Attribute)
+	} else {
+		fmt.Fprintf(b, "attr(%s)", strconv.Quote(c.Attribute))
+	}
+
+	switch c.Operator {
+	case OpExists:
+		b.WriteString(" exists")
+		return nil
+	case OpNotExists:
+		b.WriteString(" not exists")
+		return nil
+	case OpContains:
+		b.WriteString(" contains ")
+	case OpNotContains:
+		b.WriteString(" not contains ")
+	case OpIn:
+		b.WriteString(" in (")
+		values := reflect.ValueOf(c.Value)
+		if values.Kind() != reflect.Slice && values.Kind() != reflect.Array {
+			values = reflect.ValueOf([]interface{}{c.Value})
+		}
+		for i := 0; i < values.Len(); i++ {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if err := writeValue(b, c.Attribute, values.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		b.WriteString(")")
+		return nil
+	default:
+		fmt.Fprintf(b, " %s ", operatorSymbols[c.Operator])
+	}
+	return writeValue(b, c.Attribute, c.Value)
+}
+
+func writeValue(b *strings.Builder, attribute string, value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		b.WriteString(strconv.Quote(v))
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case float64:
+		b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+	case float32:
+		b.WriteString(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		fmt.Fprintf(b, "%d", v)
+	default:
+		return conditionError(ConditionAttribute, fmt.Sprintf("value %v of %s cannot be written as expression", value, attribute))
+	}
+	return nil
+}
+
+// isBareIdent return true when name can be written without attr("...")
+func isBareIdent(name string) bool {
+	if len(name) == 0 || expressionKeywords[name] {
+		return false
+	}
+	first, _ := utf8.DecodeRuneInString(name)
+	if !isIdentStart(first) {
+		return false
+	}
+	for _, r := range name {
+		if !isIdentPart(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+package pam4sdk
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/mock"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type ExpressionTestSuite struct {
+	suite.Suite
+}
+
+func TestExpressionTestSuite(t *testing.T) {
+	suite.Run(t, new(ExpressionTestSuite))
+}
+
+func (ts *ExpressionTestSuite) TestParseExpression_GivenExample_ExpectSegmentTriggers() {
+	is := assert.New(ts.T())
+	segment := &Segment{}
+
+	err := segment.SetExpression(`tag in ("vip") and event("purchase", within=30d) >= 2 and not segment("churned")`)
+
+	is.NoError(err)
+	js, _ := json.Marshal(segment.Triggers)
+	is.JSONEq(`[
+		{"type":"and","conditions":[
+			{"type":"tag","tag":"vip","operator":"has"},
+			{"type":"event","event":"purchase","operator":"gte","count":2,"within_amount":"30","within_unit":"day"},
+			{"type":"segment","segment":"churned","operator":"not_in"}
+		]}
+	]`, string(js))
+}
+
+func (ts *ExpressionTestSuite) TestParseExpression_GivenPrecedence_ExpectAndBindTighterThanOr() {
+	is := assert.New(ts.T())
+
+	c, err := ParseExpression(`age >= 18 and country = "TH" or not (city = "BKK" or vip = true)`)
+
+	is.NoError(err)
+	is.Equal(Or(
+		And(Attr("age", OpGreaterOrEqual, 18), Attr("country", OpEqual, "TH")),
+		&NotCondition{Condition: Or(Attr("city", OpEqual, "BKK"), Attr("vip", OpEqual, true))},
+	), c)
+}
+
+func (ts *ExpressionTestSuite) TestFormatCondition_GivenCanonicalExpression_ExpectSameText() {
+	is := assert.New(ts.T())
+	expressions := []string{
+		`tag in ("vip") and event("purchase", within=30d) >= 2 and not segment("churned")`,
+		`tag in ("vip", "gold") or age > 30`,
+		`not tag in ("blocked") and (email exists or mobile not exists)`,
+		`campaign("campaign_1", clicked) or not campaign("campaign_2", opened)`,
+		`event("login") = 0 and event("view", within=2mo) < 10 and event("cart", within=6h) != 1`,
+		`attrs.mobile contains "+66" and name not contains "test" and score <= -1.5`,
+		`attr("in") = "x" and attr("first name") != "" and level in (1, 2, "three", false)`,
+		`not (segment("a") and tag in ("b")) and not event("c", within=15m) >= 1`,
+	}
+
+	for _, src := range expressions {
+		c, err := ParseExpression(src)
+		if !is.NoError(err, src) {
+			continue
+		}
+		out, err := FormatCondition(c)
+		is.NoError(err)
+		is.Equal(src, out)
+	}
+}
+
+func (ts *ExpressionTestSuite) TestExpression_GivenTriggersJSON_ExpectRoundTripThroughExpression() {
+	is := assert.New(ts.T())
+	triggers := `[
+		{"type":"and","conditions":[
+			{"type":"attribute","attribute":"age","operator":"gte","value":18},
+			{"type":"tag","tag":"vip","operator":"has"},
+			{"type":"group","logic":"or","conditions":[
+				{"type":"tag","tag":"blocked","operator":"not_has"},
+				{"type":"attribute","attribute":"email","operator":"exists"}
+			]}
+		]},
+		{"type":"and","conditions":[
+			{"type":"event","event":"purchase","operator":"gte","count":3,"within_amount":"1","within_unit":"week"}
+		]},
+		{"type":"and","conditions":[
+			{"type":"not","condition":{"type":"attribute","attribute":"score","operator":"in","value":[1.5,2]}}
+		]}
+	]`
+	segment := &Segment{}
+	is.NoError(json.Unmarshal([]byte(triggers), &segment.Triggers))
+
+	expression, err := segment.Expression()
+
+	is.NoError(err)
+	is.Equal(`age >= 18 and tag in ("vip") and (not tag in ("blocked") or email exists) or event("purchase", within=1w) >= 3 or not score in (1.5, 2)`, expression)
+	parsed := &Segment{}
+	is.NoError(parsed.SetExpression(expression))
+	js, _ := json.Marshal(parsed.Triggers)
+	is.JSONEq(triggers, string(js))
+}
+
+func (ts *ExpressionTestSuite) TestParseExpression_GivenInvalidExpression_ExpectErrorPosition() {
+	is := assert.New(ts.T())
+	cases := []struct {
+		src     string
+		line    int
+		column  int
+		message string
+	}{
+		{`age >= `, 1, 8, `expected value, found end of expression`},
+		{`tag in ("vip"`, 1, 14, `expected "," or ")", found end of expression`},
+		{"age > 1 and\n  name ~ \"x\"", 2, 8, `unexpected character '~'`},
+		{`event("buy", within=3y) > 1`, 1, 21, `duration unit of 3y must be m, h, d, w or mo`},
+		{`campaign("c1", liked)`, 1, 1, `campaign condition: interaction "liked" is unknown`},
+		{`segment("a") segment("b")`, 1, 14, `expected "and", "or" or end of expression, found identifier "segment"`},
+		{`name = "unterminated`, 1, 8, `string is not terminated`},
+		{`(age > 1`, 1, 9, `expected ")", found end of expression`},
+	}
+
+	for _, c := range cases {
+		_, err := ParseExpression(c.src)
+		exprErr, ok := err.(*ExpressionError)
+		if !is.True(ok, "%s: %v", c.src, err) {
+			continue
+		}
+		is.Equal(c.line, exprErr.Pos.Line, c.src)
+		is.Equal(c.column, exprErr.Pos.Column, c.src)
+		is.Equal(c.message, exprErr.Message, c.src)
+	}
+}
+
+func (ts *ExpressionTestSuite) TestExpression_GivenSegmentFromGetSegmentByID_ExpectHumanReadable() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(nil, &RequestLogger{rq: mockRq, logger: NewMockLogger()})
+	mockRq.On("GetCtx", mock.Anything, "/triggers/seg_1", mock.Anything).Return(`{
+		"id":"seg_1","name":"Loyal",
+		"triggers":[{"type":"and","conditions":[
+			{"type":"tag","tag":"vip","operator":"has"},
+			{"type":"segment","segment":"churned","operator":"not_in"}
+		]}]
+	}`, nil)
+
+	segment, _, err := sdk.GetSegmentByIDCtx(context.Background(), "seg_1")
+	is.NoError(err)
+	expression, err := segment.Expression()
+
+	is.NoError(err)
+	is.Equal(`tag in ("vip") and not segment("churned")`, expression)
+}
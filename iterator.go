@@ -0,0 +1,263 @@
+package pam4sdk
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultPageSize is page size used by iterators when IteratorOptions does not specify it
+const DefaultPageSize = 50
+
+// Page is one page of list endpoint
+type Page[T any] struct {
+	Items []T
+	// Number is page number, the first page is 1
+	Number int
+	Size   int
+	// Total and TotalPages are set when HasTotal is true
+	Total      int
+	TotalPages int
+	HasTotal   bool
+}
+
+// PageFetcher fetch page number with size items
+type PageFetcher[T any] func(ctx context.Context, number int, size int) (*Page[T], error)
+
+// IteratorOptions control how Iterator walk pages, zero fields use default value
+type IteratorOptions struct {
+	// PageSize is number of items requested per page, default DefaultPageSize
+	PageSize int
+	// StartPage is first page fetched, default 1
+	StartPage int
+	// Prefetch fetch next page in background while current page is consumed
+	Prefetch bool
+}
+
+type fetchResult[T any] struct {
+	page *Page[T]
+	err  error
+}
+
+// Iterator walk every item of list endpoint lazily, it is not safe for concurrent use.
+// Iteration stop at the last page, on error or when ctx is done
+//
+//	it := sdk.IterateSegments(ctx, "", nil)
+//	defer it.Close()
+//	for it.Next() {
+//		segment := it.Item()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type Iterator[T any] struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	fetch  PageFetcher[T]
+	opts   IteratorOptions
+
+	page     *Page[T]
+	index    int
+	next     int
+	done     bool
+	err      error
+	prefetch chan fetchResult[T]
+}
+
+// NewIterator return iterator fetching pages with fetch, opts may be nil
+func NewIterator[T any](ctx context.Context, fetch PageFetcher[T], opts *IteratorOptions) *Iterator[T] {
+	o := IteratorOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.PageSize <= 0 {
+		o.PageSize = DefaultPageSize
+	}
+	if o.StartPage <= 0 {
+		o.StartPage = 1
+	}
+	it := &Iterator[T]{fetch: fetch, opts: o, next: o.StartPage}
+	it.ctx, it.cancel = context.WithCancel(ctx)
+	return it
+}
+
+// Next advance to the next item, it return false when there is no more item or an error occurred
+func (it *Iterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		return it.fail(err)
+	}
+	for it.page == nil || it.index+1 >= len(it.page.Items) {
+		if !it.NextPage() {
+			return false
+		}
+	}
+	it.index++
+	return true
+}
+
+// Item return current item, it is valid after Next return true
+func (it *Iterator[T]) Item() T {
+	return it.page.Items[it.index]
+}
+
+// NextPage advance to the next page, items of the page are then returned by Next.
+// It is meant for callers consuming whole pages through Page
+func (it *Iterator[T]) NextPage() bool {
+	if it.done {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		return it.fail(err)
+	}
+
+	var res fetchResult[T]
+	if it.prefetch != nil {
+		select {
+		case res = <-it.prefetch:
+		case <-it.ctx.Done():
+			return it.fail(it.ctx.Err())
+		}
+		it.prefetch = nil
+	} else {
+		res = it.get(it.next)
+	}
+	if res.err != nil {
+		return it.fail(res.err)
+	}
+
+	it.page = res.page
+	it.index = -1
+	it.next = res.page.Number + 1
+	if it.last(res.page) {
+		it.done = true
+	} else if it.opts.Prefetch {
+		it.prefetch = make(chan fetchResult[T], 1)
+		go func(ch chan fetchResult[T], number int) {
+			ch <- it.get(number)
+		}(it.prefetch, it.next)
+	}
+	return true
+}
+
+// Page return current page
+func (it *Iterator[T]) Page() *Page[T] {
+	return it.page
+}
+
+// Total return total number of items when PAM returned it
+func (it *Iterator[T]) Total() (int, bool) {
+	if it.page == nil || !it.page.HasTotal {
+		return 0, false
+	}
+	return it.page.Total, true
+}
+
+// Err return error which stopped iteration, it is nil when every page was walked
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Close stop iteration and cancel page being prefetched
+func (it *Iterator[T]) Close() {
+	it.done = true
+	it.cancel()
+}
+
+func (it *Iterator[T]) get(number int) fetchResult[T] {
+	page, err := it.fetch(it.ctx, number, it.opts.PageSize)
+	if err == nil && page == nil {
+		err = fmt.Errorf("pam page %d response is empty", number)
+	}
+	if err == nil && page.Number <= 0 {
+		page.Number = number
+	}
+	if err == nil && page.Size <= 0 {
+		page.Size = it.opts.PageSize
+	}
+	return fetchResult[T]{page: page, err: err}
+}
+
+// last return true when there is no page after page
+func (it *Iterator[T]) last(page *Page[T]) bool {
+	if len(page.Items) == 0 || len(page.Items) < page.Size {
+		return true
+	}
+	if page.HasTotal {
+		if page.TotalPages > 0 {
+			return page.Number >= page.TotalPages
+		}
+		return page.Number*page.Size >= page.Total
+	}
+	return false
+}
+
+func (it *Iterator[T]) fail(err error) bool {
+	it.err = err
+	it.done = true
+	it.cancel()
+	return false
+}
+
+// newPage return page of items with paging information returned by PAM
+func newPage[T any](p Pagination, items []T, number int, size int) *Page[T] {
+	if p.Page > 0 {
+		number = p.Page
+	}
+	if p.Limit > 0 {
+		size = p.Limit
+	}
+	return &Page[T]{
+		Items:      items,
+		Number:     number,
+		Size:       size,
+		Total:      p.Total,
+		TotalPages: p.TotalPage,
+		HasTotal:   p.Total > 0 || p.TotalPage > 0,
+	}
+}
+
+// IterateSegments walk every segment matching q
+func (sdk *Sdk) IterateSegments(ctx context.Context, q string, opts *IteratorOptions) *Iterator[*SegmentResponse] {
+	return NewIterator(ctx, func(ctx context.Context, number int, size int) (*Page[*SegmentResponse], error) {
+		res, _, err := sdk.GetSegmentsCtx(ctx, q, number, size)
+		if err != nil {
+			return nil, err
+		}
+		return newPage(res.Pagination, res.Segments, number, size), nil
+	}, opts)
+}
+
+// IterateCampaigns walk every campaign matching query, page and limit of query are set by iterator
+func (sdk *Sdk) IterateCampaigns(ctx context.Context, query *CampaignQuery, opts *IteratorOptions) *Iterator[*CampaignResponse] {
+	q := CampaignQuery{}
+	if query != nil {
+		q = *query
+	}
+	return NewIterator(ctx, func(ctx context.Context, number int, size int) (*Page[*CampaignResponse], error) {
+		pq := q
+		pq.Page, pq.Limit = number, size
+		res, _, err := sdk.FindCampaignsCtx(ctx, &pq)
+		if err != nil {
+			return nil, err
+		}
+		return newPage(res.Pagination, res.Campaigns, number, size), nil
+	}, opts)
+}
+
+// IterateContacts walk every contact matching query, page and limit of query are set by iterator
+func (sdk *Sdk) IterateContacts(ctx context.Context, query *ContactQuery, opts *IteratorOptions) *Iterator[*Contact] {
+	q := ContactQuery{}
+	if query != nil {
+		q = *query
+	}
+	return NewIterator(ctx, func(ctx context.Context, number int, size int) (*Page[*Contact], error) {
+		pq := q
+		pq.Page, pq.Limit = number, size
+		res, _, err := sdk.FindContactsCtx(ctx, &pq)
+		if err != nil {
+			return nil, err
+		}
+		return newPage(res.Pagination, res.Contacts, number, size), nil
+	}, opts)
+}
@@ -0,0 +1,179 @@
+package pam4sdk
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/mock"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type IteratorTestSuite struct {
+	suite.Suite
+}
+
+func TestIteratorTestSuite(t *testing.T) {
+	suite.Run(t, new(IteratorTestSuite))
+}
+
+// numbers return fetcher serving total numbers, hasTotal decide whether total is reported
+func (ts *IteratorTestSuite) numbers(total int, hasTotal bool, fetched chan<- int) PageFetcher[int] {
+	return func(ctx context.Context, number int, size int) (*Page[int], error) {
+		if fetched != nil {
+			fetched <- number
+		}
+		items := []int{}
+		for i := (number - 1) * size; i < number*size && i < total; i++ {
+			items = append(items, i)
+		}
+		page := &Page[int]{Items: items, Number: number, Size: size}
+		if hasTotal {
+			page.Total = total
+			page.HasTotal = true
+		}
+		return page, nil
+	}
+}
+
+func (ts *IteratorTestSuite) TestNext_GivenTotal_ExpectEveryItemWalkedWithoutExtraFetch() {
+	is := assert.New(ts.T())
+	fetched := make(chan int, 10)
+	it := NewIterator(context.Background(), ts.numbers(4, true, fetched), &IteratorOptions{PageSize: 2})
+	defer it.Close()
+
+	items := []int{}
+	for it.Next() {
+		items = append(items, it.Item())
+	}
+	is.NoError(it.Err())
+	is.Equal([]int{0, 1, 2, 3}, items)
+	is.Len(fetched, 2)
+	total, ok := it.Total()
+	is.True(ok)
+	is.Equal(4, total)
+}
+
+func (ts *IteratorTestSuite) TestNext_GivenStartPageAndTotal_ExpectNoExtraFetch() {
+	is := assert.New(ts.T())
+	fetched := make(chan int, 10)
+	it := NewIterator(context.Background(), ts.numbers(6, true, fetched), &IteratorOptions{PageSize: 2, StartPage: 2})
+	defer it.Close()
+
+	items := []int{}
+	for it.Next() {
+		items = append(items, it.Item())
+	}
+	is.NoError(it.Err())
+	is.Equal([]int{2, 3, 4, 5}, items)
+	close(fetched)
+	pages := []int{}
+	for number := range fetched {
+		pages = append(pages, number)
+	}
+	is.Equal([]int{2, 3}, pages)
+}
+
+func (ts *IteratorTestSuite) TestNext_GivenNoTotal_ExpectStopAtShortPage() {
+	is := assert.New(ts.T())
+	it := NewIterator(context.Background(), ts.numbers(5, false, nil), &IteratorOptions{PageSize: 2})
+	defer it.Close()
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+	is.NoError(it.Err())
+	is.Equal(5, count)
+	_, ok := it.Total()
+	is.False(ok)
+}
+
+func (ts *IteratorTestSuite) TestNext_GivenContextCancelled_ExpectStopWithError() {
+	is := assert.New(ts.T())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it := NewIterator(ctx, ts.numbers(100, true, nil), &IteratorOptions{PageSize: 10})
+	defer it.Close()
+
+	count := 0
+	for it.Next() {
+		count++
+		if count == 3 {
+			cancel()
+		}
+	}
+	is.Equal(3, count)
+	is.Equal(context.Canceled, it.Err())
+}
+
+func (ts *IteratorTestSuite) TestNext_GivenPrefetch_ExpectNextPageFetchedBeforeCurrentIsConsumed() {
+	is := assert.New(ts.T())
+	fetched := make(chan int, 10)
+	it := NewIterator(context.Background(), ts.numbers(6, true, fetched), &IteratorOptions{PageSize: 2, Prefetch: true})
+	defer it.Close()
+
+	is.True(it.Next())
+	is.Equal(1, <-fetched)
+	select {
+	case number := <-fetched:
+		is.Equal(2, number)
+	case <-time.After(time.Second):
+		is.Fail("page 2 was not prefetched")
+	}
+
+	items := []int{it.Item()}
+	for it.Next() {
+		items = append(items, it.Item())
+	}
+	is.NoError(it.Err())
+	is.Equal([]int{0, 1, 2, 3, 4, 5}, items)
+}
+
+func (ts *IteratorTestSuite) TestIterateSegments_GivenTwoPages_ExpectPagesRequestedInOrder() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(nil, &RequestLogger{rq: mockRq, logger: NewMockLogger()})
+
+	for page, body := range []string{
+		`{"page":1,"limit":2,"total":3,"total_page":2,"data":[{"id":"s1"},{"id":"s2"}]}`,
+		`{"page":2,"limit":2,"total":3,"total_page":2,"data":[{"id":"s3"}]}`,
+	} {
+		p := map[string]string{"page": fmt.Sprintf("%d", page+1), "limit": "2"}
+		mockRq.On("GetCtx", mock.Anything, "/triggers", p).Return(body, nil).Once()
+	}
+
+	it := sdk.IterateSegments(context.Background(), "", &IteratorOptions{PageSize: 2})
+	defer it.Close()
+	ids := []string{}
+	for it.Next() {
+		ids = append(ids, it.Item().ID)
+	}
+	if is.NoError(it.Err()) {
+		is.Equal([]string{"s1", "s2", "s3"}, ids)
+		mockRq.AssertExpectations(ts.T())
+	}
+}
+
+func (ts *IteratorTestSuite) TestIterateContacts_GivenTags_ExpectTagEndpointRequested() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(&RequestLogger{rq: mockRq, logger: NewMockLogger()}, nil)
+
+	p := map[string]string{"tags": "vip,gold", "page": "1", "limit": "2"}
+	mockRq.On("GetCtx", mock.Anything, "/api/contacts/tag/multiple", p).
+		Return(`{"page":1,"limit":2,"total":1,"total_page":1,"data":[{"contact_id":"c1"}]}`, nil).Once()
+
+	it := sdk.IterateContacts(context.Background(), &ContactQuery{Tags: []string{"vip", "gold"}}, &IteratorOptions{PageSize: 2})
+	defer it.Close()
+	ids := []string{}
+	for it.Next() {
+		ids = append(ids, it.Item().ContactID)
+	}
+	if is.NoError(it.Err()) {
+		is.Equal([]string{"c1"}, ids)
+		mockRq.AssertExpectations(ts.T())
+	}
+}
@@ -0,0 +1,194 @@
+package pam4sdk
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// StatusClassError is status class reported when no response was received
+const StatusClassError = "error"
+
+// DefaultLatencyBuckets is upper bounds in seconds of latency histogram buckets
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// RequestMetrics describe a finished request, including all of its attempts
+type RequestMetrics struct {
+	// Connector is connector name, ConnectorConnect or ConnectorCMS
+	Connector string
+	// Method is HTTP method
+	Method string
+	// Route is path template such as /campaigns/{id}
+	Route string
+	// StatusCode is status of last response, 0 when no response was received
+	StatusCode int
+	// StatusClass is 2xx, 3xx, 4xx, 5xx or StatusClassError when no response was received
+	StatusClass string
+	// Attempts is number of attempts made
+	Attempts int
+	// Duration is time spent including retries
+	Duration time.Duration
+}
+
+// MetricsCollector receive metrics of every request, it must be safe for concurrent use
+type MetricsCollector interface {
+	ObserveRequest(m RequestMetrics)
+}
+
+// MetricsMiddleware report every request to collector, nil collector is disabled
+func MetricsMiddleware(collector MetricsCollector) Middleware {
+	return func(next Handler) Handler {
+		if collector == nil {
+			return next
+		}
+		return func(ctx context.Context, req *Request) (*http.Response, string, error) {
+			start := time.Now()
+			res, body, err := next(ctx, req)
+
+			m := RequestMetrics{
+				Connector:   req.Connector,
+				Method:      req.Method,
+				Route:       req.Route,
+				StatusClass: StatusClassError,
+				Attempts:    req.Attempt,
+				Duration:    time.Since(start),
+			}
+			if m.Attempts < 1 {
+				m.Attempts = 1
+			}
+			if err == nil && res != nil {
+				m.StatusCode = res.StatusCode
+				m.StatusClass = fmt.Sprintf("%dxx", res.StatusCode/100)
+			}
+			collector.ObserveRequest(m)
+			return res, body, err
+		}
+	}
+}
+
+// Histogram is snapshot of latency histogram, Counts[i] is number of observations <= Buckets[i]
+// and the last element of Counts is number of observations above every bucket
+type Histogram struct {
+	Buckets []float64 `json:"buckets"`
+	Counts  []int64   `json:"counts"`
+	Count   int64     `json:"count"`
+	Sum     float64   `json:"sum"`
+}
+
+func (h *Histogram) observe(v float64) {
+	i := sort.SearchFloat64s(h.Buckets, v)
+	h.Counts[i]++
+	h.Count++
+	h.Sum += v
+}
+
+// EndpointMetrics is counters of one connector, method and route
+type EndpointMetrics struct {
+	Connector string           `json:"connector"`
+	Method    string           `json:"method"`
+	Route     string           `json:"route"`
+	Requests  int64            `json:"requests"`
+	Attempts  int64            `json:"attempts"`
+	Status    map[string]int64 `json:"status"`
+	Latency   Histogram        `json:"latency_seconds"`
+}
+
+// ExpvarMetrics is in-memory MetricsCollector and ConsentMetricsCollector which can be published through expvar
+type ExpvarMetrics struct {
+	mu        sync.Mutex
+	buckets   []float64
+	endpoints map[string]*EndpointMetrics
+	consent   map[string]int64
+}
+
+// NewExpvarMetrics return collector using DefaultLatencyBuckets when buckets is empty
+func NewExpvarMetrics(buckets ...float64) *ExpvarMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+	return &ExpvarMetrics{
+		buckets:   buckets,
+		endpoints: map[string]*EndpointMetrics{},
+		consent:   map[string]int64{},
+	}
+}
+
+// ObserveRequest implement MetricsCollector
+func (m *ExpvarMetrics) ObserveRequest(rm RequestMetrics) {
+	key := fmt.Sprintf("%s %s %s", rm.Connector, rm.Method, rm.Route)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.endpoints[key]
+	if !ok {
+		e = &EndpointMetrics{
+			Connector: rm.Connector,
+			Method:    rm.Method,
+			Route:     rm.Route,
+			Status:    map[string]int64{},
+			Latency: Histogram{
+				Buckets: m.buckets,
+				Counts:  make([]int64, len(m.buckets)+1),
+			},
+		}
+		m.endpoints[key] = e
+	}
+	e.Requests++
+	e.Attempts += int64(rm.Attempts)
+	e.Status[rm.StatusClass]++
+	e.Latency.observe(rm.Duration.Seconds())
+}
+
+// Snapshot return copy of metrics keyed by "connector method route"
+func (m *ExpvarMetrics) Snapshot() map[string]*EndpointMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := make(map[string]*EndpointMetrics, len(m.endpoints))
+	for key, e := range m.endpoints {
+		c := *e
+		c.Status = make(map[string]int64, len(e.Status))
+		for class, count := range e.Status {
+			c.Status[class] = count
+		}
+		c.Latency.Counts = append([]int64{}, e.Latency.Counts...)
+		snapshot[key] = &c
+	}
+	return snapshot
+}
+
+// ObserveConsent implement ConsentMetricsCollector
+func (m *ExpvarMetrics) ObserveConsent(cm ConsentMetrics) {
+	key := fmt.Sprintf("%s %s", cm.Purpose, cm.Decision)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.consent[key]++
+}
+
+// ConsentSnapshot return copy of consent decision counters keyed by "purpose decision"
+func (m *ExpvarMetrics) ConsentSnapshot() map[string]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := make(map[string]int64, len(m.consent))
+	for key, count := range m.consent {
+		snapshot[key] = count
+	}
+	return snapshot
+}
+
+// Publish expose request metrics as expvar variable with name and consent decisions as name.consent,
+// it panics when name is already published
+func (m *ExpvarMetrics) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return m.Snapshot()
+	}))
+	expvar.Publish(name+".consent", expvar.Func(func() interface{} {
+		return m.ConsentSnapshot()
+	}))
+}
@@ -0,0 +1,120 @@
+package pam4sdk
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type MetricsTestSuite struct {
+	suite.Suite
+}
+
+func TestMetricsTestSuite(t *testing.T) {
+	suite.Run(t, new(MetricsTestSuite))
+}
+
+func (ts *MetricsTestSuite) TestGetSegmentByIDCtx_GivenMetrics_ExpectRouteTemplateRecorded() {
+	is := assert.New(ts.T())
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte(`{"id":"segment_123"}`))
+	}))
+	defer server.Close()
+
+	metrics := NewExpvarMetrics()
+	sdk := NewClient(
+		WithCMS(&SDKConnector{BaseURL: server.URL, AppID: "app-id", AppSecret: "secret"}),
+		WithMetrics(metrics),
+	)
+	_, _, err := sdk.GetSegmentByIDCtx(context.Background(), "segment_123")
+	is.NoError(err)
+
+	snapshot := metrics.Snapshot()
+	if e, ok := snapshot["cms GET /triggers/{id}"]; is.True(ok) {
+		is.Equal(ConnectorCMS, e.Connector)
+		is.Equal(int64(1), e.Requests)
+		is.Equal(int64(1), e.Attempts)
+		is.Equal(map[string]int64{"2xx": 1}, e.Status)
+		is.Equal(int64(1), e.Latency.Count)
+	}
+}
+
+func (ts *MetricsTestSuite) TestGet_GivenRetriedRequest_ExpectAttemptsAndLastStatusRecorded() {
+	is := assert.New(ts.T())
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	var observed []RequestMetrics
+	collector := metricsFunc(func(m RequestMetrics) {
+		observed = append(observed, m)
+	})
+	cfg := NewCustomRequesterConfig(server.URL, "x-app-id", "x-secret", "app-id", "secret", 2*time.Second)
+	rqt := NewRequester(cfg, NewLoggerSimple(),
+		RequesterConnector(ConnectorConnect),
+		RequesterMetrics(collector),
+		RequesterRetryPolicy(&RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}))
+
+	_, err := rqt.Get("/abc", nil)
+	is.True(IsNotFound(err))
+	if is.Len(observed, 1) {
+		is.Equal(ConnectorConnect, observed[0].Connector)
+		is.Equal("/abc", observed[0].Route)
+		is.Equal(2, observed[0].Attempts)
+		is.Equal(http.StatusNotFound, observed[0].StatusCode)
+		is.Equal("4xx", observed[0].StatusClass)
+	}
+}
+
+func (ts *MetricsTestSuite) TestObserveRequest_GivenDurations_ExpectHistogramBuckets() {
+	is := assert.New(ts.T())
+	metrics := NewExpvarMetrics(0.1, 1)
+	m := RequestMetrics{Connector: ConnectorConnect, Method: "GET", Route: "/abc", StatusClass: StatusClassError, Attempts: 1}
+	for _, d := range []time.Duration{50 * time.Millisecond, 500 * time.Millisecond, 2 * time.Second} {
+		m.Duration = d
+		metrics.ObserveRequest(m)
+	}
+
+	e := metrics.Snapshot()["connect GET /abc"]
+	is.Equal([]int64{1, 1, 1}, e.Latency.Counts)
+	is.Equal(int64(3), e.Latency.Count)
+	is.InDelta(2.55, e.Latency.Sum, 0.001)
+	is.Equal(int64(3), e.Status[StatusClassError])
+}
+
+func (ts *MetricsTestSuite) TestPublish_GivenName_ExpectMetricsInExpvar() {
+	is := assert.New(ts.T())
+	metrics := NewExpvarMetrics()
+	metrics.ObserveRequest(RequestMetrics{Connector: ConnectorCMS, Method: "GET", Route: "/media", StatusClass: "2xx", Attempts: 1})
+	// Name must be unique because expvar cannot unpublish
+	name := fmt.Sprintf("pam4sdk_metrics_test_%d", time.Now().UnixNano())
+	metrics.Publish(name)
+
+	v := expvar.Get(name)
+	if is.NotNil(v) {
+		snapshot := map[string]*EndpointMetrics{}
+		is.NoError(json.Unmarshal([]byte(v.String()), &snapshot))
+		is.Equal(int64(1), snapshot["cms GET /media"].Requests)
+	}
+}
+
+type metricsFunc func(m RequestMetrics)
+
+func (f metricsFunc) ObserveRequest(m RequestMetrics) {
+	f(m)
+}
@@ -0,0 +1,125 @@
+package pam4sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Request is outgoing request seen by middlewares, middlewares may change it before calling next
+type Request struct {
+	// Method is HTTP method
+	Method string
+	// URL is full URL including query string
+	URL string
+	// Path is path relative to connector endpoint, as passed to IRequester
+	Path string
+	// Route is path template such as /campaigns/{id}, it is Path when caller did not give one
+	Route string
+	// Connector is name of connector sending the request, such as ConnectorConnect
+	Connector string
+	// Header contain credential, content type and headers passed to IRequester
+	Header http.Header
+	// Cookies are sent with request
+	Cookies []*http.Cookie
+	// Body is request body, it is sent again on every attempt
+	Body []byte
+	// Attempt is number of current attempt starting from 1, it is set by retry middleware
+	Attempt int
+}
+
+type routeKey struct{}
+
+// withRoute attach path template of the request about to be sent to ctx
+func withRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+func routeFromContext(ctx context.Context) (string, bool) {
+	route, ok := ctx.Value(routeKey{}).(string)
+	return route, ok && len(route) > 0
+}
+
+// Handler send Request and return response with its body,
+// error is returned only when no response was received so 4xx and 5xx responses are not errors
+type Handler func(ctx context.Context, req *Request) (*http.Response, string, error)
+
+// Middleware wrap Handler to run code around every request
+type Middleware func(next Handler) Handler
+
+// Chain wrap h with middlewares, the first middleware is the outermost
+func Chain(h Handler, mws ...Middleware) Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		if mws[i] != nil {
+			h = mws[i](h)
+		}
+	}
+	return h
+}
+
+// RetryMiddleware retry request according to policy, nil policy never retry.
+// It set Request.Attempt so outer middlewares can read number of attempts after next return
+func RetryMiddleware(policy *RetryPolicy, logger ILogger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*http.Response, string, error) {
+			retryable := policy.allowed(req.Method, req.Header)
+			for attempt := 1; ; attempt++ {
+				req.Attempt = attempt
+				res, body, err := next(ctx, req)
+				if err == ErrCircuitOpen {
+					return nil, "", err
+				}
+				if !retryable || ctx.Err() != nil || !policy.shouldRetry(attempt, res, err) {
+					return res, body, err
+				}
+
+				wait := policy.backoff(attempt, res)
+				if logger != nil {
+					logger.Debug(fmt.Sprintf("[RQT RETRY]: %s %s attempt %d in %s", req.Method, req.URL, attempt+1, wait))
+				}
+				if err := sleepCtx(ctx, wait); err != nil {
+					return nil, "", err
+				}
+			}
+		}
+	}
+}
+
+// CircuitBreakerMiddleware fail fast with ErrCircuitOpen while breaker is open, nil breaker is disabled
+func CircuitBreakerMiddleware(breaker *CircuitBreaker) Middleware {
+	return func(next Handler) Handler {
+		if breaker == nil {
+			return next
+		}
+		return func(ctx context.Context, req *Request) (*http.Response, string, error) {
+			if err := breaker.allow(); err != nil {
+				return nil, "", err
+			}
+			res, body, err := next(ctx, req)
+			if ctx.Err() != nil {
+				// Cancelled by caller, it says nothing about PAM health
+				breaker.release()
+			} else {
+				breaker.record(err == nil && res.StatusCode < 500)
+			}
+			return res, body, err
+		}
+	}
+}
+
+// RateLimitMiddleware make every request wait for limiter, nil limiter is unlimited
+func RateLimitMiddleware(limiter *RateLimiter) Middleware {
+	return func(next Handler) Handler {
+		if limiter == nil {
+			return next
+		}
+		return func(ctx context.Context, req *Request) (*http.Response, string, error) {
+			if err := limiter.Wait(ctx); err != nil {
+				return nil, "", err
+			}
+			res, body, err := next(ctx, req)
+			limiter.observe(res)
+			return res, body, err
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package pam4sdk
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+}
+
+func TestMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
+
+func (ts *MiddlewareTestSuite) newRequester(url string, opts ...RequesterOption) *Requester {
+	cfg := NewCustomRequesterConfig(url, "x-app-id", "x-secret", "my-app-id-1234", "my-secret-1234", 2*time.Second)
+	return NewRequester(cfg, NewLoggerSimple(), opts...)
+}
+
+func (ts *MiddlewareTestSuite) TestChain_GivenMiddlewares_ExpectFirstIsOutermost() {
+	is := assert.New(ts.T())
+	var order []string
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, req *Request) (*http.Response, string, error) {
+				order = append(order, name+"-before")
+				res, body, err := next(ctx, req)
+				order = append(order, name+"-after")
+				return res, body, err
+			}
+		}
+	}
+	h := Chain(func(ctx context.Context, req *Request) (*http.Response, string, error) {
+		order = append(order, "handler")
+		return nil, "", nil
+	}, record("a"), nil, record("b"))
+
+	h(context.Background(), &Request{})
+	is.Equal([]string{"a-before", "b-before", "handler", "b-after", "a-after"}, order)
+}
+
+func (ts *MiddlewareTestSuite) TestPostJSON_GivenMiddleware_ExpectRequestAndResponseVisible() {
+	is := assert.New(ts.T())
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		is.Equal("audit-1", req.Header.Get("x-audit"))
+		body, _ := ioutil.ReadAll(req.Body)
+		is.Equal(`{"a":"changed"}`, string(body))
+		rw.WriteHeader(http.StatusCreated)
+		rw.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	var seen *Request
+	var status int
+	mw := func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*http.Response, string, error) {
+			seen = req
+			req.Header.Set("x-audit", "audit-1")
+			req.Body = []byte(strings.Replace(string(req.Body), "abc", "changed", 1))
+			res, body, err := next(ctx, req)
+			if err == nil {
+				status = res.StatusCode
+			}
+			return res, body, err
+		}
+	}
+
+	rqt := ts.newRequester(server.URL, RequesterMiddleware(mw))
+	result, err := rqt.PostJSON("/abc", map[string]string{"a": "abc"})
+	if is.NoError(err) {
+		is.Equal("OK", result)
+		is.Equal(http.MethodPost, seen.Method)
+		is.Equal(server.URL+"/abc", seen.URL)
+		is.Equal("/abc", seen.Path)
+		is.Equal("my-app-id-1234", seen.Header.Get("x-app-id"))
+		is.Equal("application/json", seen.Header.Get("Content-Type"))
+		is.Equal(http.StatusCreated, status)
+	}
+}
+
+func (ts *MiddlewareTestSuite) TestGet_GivenRetryPolicy_ExpectMiddlewareCalledOnceWithAttempts() {
+	is := assert.New(ts.T())
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	var invoked, attempts int
+	mw := func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*http.Response, string, error) {
+			invoked++
+			res, body, err := next(ctx, req)
+			attempts = req.Attempt
+			return res, body, err
+		}
+	}
+
+	policy := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	rqt := ts.newRequester(server.URL, RequesterMiddleware(mw), RequesterRetryPolicy(policy))
+	result, err := rqt.Get("/abc", nil)
+	if is.NoError(err) {
+		is.Equal("OK", result)
+		is.Equal(1, invoked)
+		is.Equal(2, attempts)
+	}
+}
@@ -0,0 +1,217 @@
+package pam4sdk
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultRequestTimeout is timeout used when connector does not specify RequestTimeout
+const DefaultRequestTimeout = 10 * time.Second
+
+// Connector names used in errors and diagnostics
+const (
+	ConnectorConnect = "connect"
+	ConnectorCMS     = "cms"
+)
+
+// Option configure client created by NewClient
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	connect        *SDKConnector
+	cms            *SDKConnector
+	logger         ILogger
+	tracer         Tracer
+	contacts       ContactStore
+	consent        ConsentConfig
+	pii            PIIRules
+	metrics        MetricsCollector
+	httpClient     *http.Client
+	timeout        time.Duration
+	requesterOpts  []RequesterOption
+	connectRqtOpts []RequesterOption
+	cmsRqtOpts     []RequesterOption
+}
+
+// WithConnect set credential for PAM connect API (tracking, contacts, reports)
+func WithConnect(connector *SDKConnector) Option {
+	return func(o *clientOptions) {
+		o.connect = connector
+	}
+}
+
+// WithCMS set credential for PAM CMS API (segments, campaigns, media)
+func WithCMS(connector *SDKConnector) Option {
+	return func(o *clientOptions) {
+		o.cms = connector
+	}
+}
+
+// WithLogger set logger for client and its requesters
+func WithLogger(logger ILogger) Option {
+	return func(o *clientOptions) {
+		o.logger = logger
+	}
+}
+
+// WithTracer start span for every ISdk method and HTTP attempt, default is NoopTracer
+func WithTracer(tracer Tracer) Option {
+	return func(o *clientOptions) {
+		o.tracer = tracer
+	}
+}
+
+// WithContactStore load contact id of events sent without one from store and save contact id resolved by PAM,
+// sessions are told apart by ContextWithSessionID
+func WithContactStore(store ContactStore) Option {
+	return func(o *clientOptions) {
+		o.contacts = store
+	}
+}
+
+// WithPII transform PII fields of every event, contact and notification lookup before it is sent to PAM
+func WithPII(rules PIIRules) Option {
+	return func(o *clientOptions) {
+		o.pii = rules
+	}
+}
+
+// WithHTTPClient set http client used for sending request to PAM
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = client
+	}
+}
+
+// WithTransport set round tripper used for sending request to PAM, it takes precedence over WithHTTPClient
+func WithTransport(transport http.RoundTripper) Option {
+	return WithRequesterOptions(RequesterTransport(transport))
+}
+
+// WithTimeout set request timeout for connectors which do not specify RequestTimeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithRequesterOptions apply requester options to both connect and CMS requesters
+func WithRequesterOptions(opts ...RequesterOption) Option {
+	return func(o *clientOptions) {
+		o.requesterOpts = append(o.requesterOpts, opts...)
+	}
+}
+
+// WithConnectOptions apply requester options to connect requester only
+func WithConnectOptions(opts ...RequesterOption) Option {
+	return func(o *clientOptions) {
+		o.connectRqtOpts = append(o.connectRqtOpts, opts...)
+	}
+}
+
+// WithCMSOptions apply requester options to CMS requester only
+func WithCMSOptions(opts ...RequesterOption) Option {
+	return func(o *clientOptions) {
+		o.cmsRqtOpts = append(o.cmsRqtOpts, opts...)
+	}
+}
+
+// WithMiddleware wrap every request of both connectors with middlewares, the first one is the outermost
+func WithMiddleware(mws ...Middleware) Option {
+	return WithRequesterOptions(RequesterMiddleware(mws...))
+}
+
+// WithMetrics report requests of both connectors to collector, collector implementing
+// ConsentMetricsCollector also receive consent decisions
+func WithMetrics(collector MetricsCollector) Option {
+	return func(o *clientOptions) {
+		o.metrics = collector
+		o.requesterOpts = append(o.requesterOpts, RequesterMetrics(collector))
+	}
+}
+
+// WithConsent check consent of contact before sending event
+func WithConsent(config ConsentConfig) Option {
+	return func(o *clientOptions) {
+		o.consent = config
+	}
+}
+
+// WithRetryPolicy retry failed requests of both connectors according to policy
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return WithRequesterOptions(RequesterRetryPolicy(policy))
+}
+
+// WithRateLimiters limit requests of connect and CMS connectors separately, nil limiter is unlimited
+func WithRateLimiters(connect *RateLimiter, cms *RateLimiter) Option {
+	return func(o *clientOptions) {
+		if connect != nil {
+			o.connectRqtOpts = append(o.connectRqtOpts, RequesterRateLimiter(connect))
+		}
+		if cms != nil {
+			o.cmsRqtOpts = append(o.cmsRqtOpts, RequesterRateLimiter(cms))
+		}
+	}
+}
+
+// WithCircuitBreakers guard connect and CMS connectors with separate circuit breakers, nil breaker is disabled
+func WithCircuitBreakers(connect *CircuitBreaker, cms *CircuitBreaker) Option {
+	return func(o *clientOptions) {
+		if connect != nil {
+			o.connectRqtOpts = append(o.connectRqtOpts, RequesterCircuitBreaker(connect))
+		}
+		if cms != nil {
+			o.cmsRqtOpts = append(o.cmsRqtOpts, RequesterCircuitBreaker(cms))
+		}
+	}
+}
+
+// NewClient create client from options, connect and CMS connectors are both optional
+// but calling a method whose connector is not configured return ConnectorNotConfiguredError
+func NewClient(opts ...Option) *Sdk {
+	o := &clientOptions{
+		timeout: DefaultRequestTimeout,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.logger == nil {
+		o.logger = NewLoggerSimple()
+	}
+
+	return &Sdk{
+		connect:  o.requestLogger(ConnectorConnect, o.connect, o.connectRqtOpts),
+		cms:      o.requestLogger(ConnectorCMS, o.cms, o.cmsRqtOpts),
+		tracer:   o.tracer,
+		metrics:  o.metrics,
+		contacts: o.contacts,
+		consent:  o.consent,
+		pii:      o.pii,
+	}
+}
+
+func (o *clientOptions) requestLogger(name string, connector *SDKConnector, connectorOpts []RequesterOption) *RequestLogger {
+	if connector == nil {
+		return nil
+	}
+	timeout := connector.RequestTimeout
+	if timeout <= 0 {
+		timeout = o.timeout
+	}
+	config := NewCustomRequesterConfig(
+		connector.BaseURL,
+		"x-app-id",
+		"x-secret",
+		connector.AppID,
+		connector.AppSecret,
+		timeout)
+	opts := []RequesterOption{
+		RequesterConnector(name),
+		RequesterHTTPClient(o.httpClient),
+		RequesterTracer(o.tracer),
+	}
+	opts = append(opts, o.requesterOpts...)
+	opts = append(opts, connectorOpts...)
+	rq := NewRequester(config, o.logger, opts...)
+	return &RequestLogger{rq: rq, logger: o.logger}
+}
@@ -0,0 +1,83 @@
+package pam4sdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type OptionsTestSuite struct {
+	suite.Suite
+}
+
+func TestOptionsTestSuite(t *testing.T) {
+	suite.Run(t, new(OptionsTestSuite))
+}
+
+func (ts *OptionsTestSuite) TestNewSdk_GivenConnectOnly_ExpectNoPanic() {
+	is := assert.New(ts.T())
+	is.NotPanics(func() {
+		sdk := NewSdk("http://localhost", "app-id", "secret")
+		is.NotNil(sdk.connect)
+		is.Nil(sdk.cms)
+	})
+}
+
+func (ts *OptionsTestSuite) TestNewClient_GivenCMSOnly_ExpectConnectMethodReturnNotConfigured() {
+	is := assert.New(ts.T())
+	sdk := NewClient(WithCMS(&SDKConnector{BaseURL: "http://localhost", AppID: "app-id", AppSecret: "secret"}))
+
+	_, err := sdk.SendEvent("contact_123", "", &Tracker{Event: "event_1234"})
+	if is.Error(err) {
+		e, ok := err.(*ConnectorNotConfiguredError)
+		if is.True(ok) {
+			is.Equal(ConnectorConnect, e.Connector)
+		}
+	}
+}
+
+func (ts *OptionsTestSuite) TestNewClient_GivenConnectOnly_ExpectCMSMethodReturnNotConfigured() {
+	is := assert.New(ts.T())
+	sdk := NewClient(WithConnect(&SDKConnector{BaseURL: "http://localhost", AppID: "app-id", AppSecret: "secret"}))
+
+	_, err := sdk.GetSegments("", 1, 10)
+	is.Equal(&ConnectorNotConfiguredError{Connector: ConnectorCMS}, err)
+}
+
+func (ts *OptionsTestSuite) TestNewClient_GivenHTTPClient_ExpectRequestSentThroughClient() {
+	is := assert.New(ts.T())
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		is.Equal("/api/products/trends?limit=10", req.URL.String())
+		is.Equal("app-id", req.Header.Get("x-app-id"))
+		is.Equal("secret", req.Header.Get("x-secret"))
+		rw.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{next: http.DefaultTransport}
+	sdk := NewClient(
+		WithConnect(&SDKConnector{BaseURL: server.URL, AppID: "app-id", AppSecret: "secret"}),
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithTimeout(time.Second),
+	)
+
+	res, err := sdk.ProductTrends(10)
+	if is.NoError(err) {
+		is.Equal("OK", res)
+		is.Equal(1, transport.count)
+	}
+}
+
+type countingTransport struct {
+	next  http.RoundTripper
+	count int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.count++
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,187 @@
+package pam4sdk
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// DefaultPageViewEvent is event name sent by PageViewMiddleware when EventName is not set
+const DefaultPageViewEvent = "pageview"
+
+type contactIDKey struct{}
+
+// ContextWithContactID return ctx carrying PAM contact id of the visitor
+func ContextWithContactID(ctx context.Context, contactID string) context.Context {
+	return context.WithValue(ctx, contactIDKey{}, contactID)
+}
+
+// ContactIDFromContext return contact id set by PageViewMiddleware
+func ContactIDFromContext(ctx context.Context) (string, bool) {
+	contactID, ok := ctx.Value(contactIDKey{}).(string)
+	return contactID, ok && len(contactID) > 0
+}
+
+// PageViewConfig is configuration of PageViewMiddleware, zero fields use default value
+type PageViewConfig struct {
+	// Methods are HTTP methods tracked, default GET
+	Methods []string
+	// Include are path patterns to track, default every path. Pattern is path.Match
+	// pattern, or prefix when it ends with "/"
+	Include []string
+	// Exclude are path patterns never tracked, it take precedence over Include
+	Exclude []string
+	// EventName return event name of request, default DefaultPageViewEvent
+	EventName func(r *http.Request) string
+	// Tracker is options given to NewTrackerFromRequest
+	Tracker TrackerOptions
+	// IdentifyNewVisitors send events of visitors without contact_id cookie before calling next handler,
+	// so contact id from PAM is set as cookie on their first page view. It add PAM latency, up to
+	// NewVisitorTimeout, to every request without cookie, including bots which never keep cookies
+	IdentifyNewVisitors bool
+	// NewVisitorTimeout bound time spent identifying new visitor, and sending event from a new
+	// goroutine when dispatcher is nil, default 2 seconds
+	NewVisitorTimeout time.Duration
+	// CookieMaxAge is lifetime of contact_id cookie, default 1 year
+	CookieMaxAge time.Duration
+	// CookieDomain is domain of contact_id cookie, default host only
+	CookieDomain string
+	// CookieSecure send contact_id cookie over HTTPS only
+	CookieSecure bool
+	// OnError is called when event cannot be sent or dispatched
+	OnError func(r *http.Request, err error)
+}
+
+// PageViewMiddleware send page view event for every matched request and set or refresh contact_id cookie.
+// Events are queued in dispatcher without waiting, an event which does not fit in the queue is dropped,
+// or sent from a new goroutine when dispatcher is nil, so responses never wait for PAM unless
+// PageViewConfig.IdentifyNewVisitors is set. Contact id is available to next handler through
+// ContactIDFromContext
+func PageViewMiddleware(sender EventSender, dispatcher *EventDispatcher, config PageViewConfig) func(http.Handler) http.Handler {
+	if len(config.Methods) == 0 {
+		config.Methods = []string{http.MethodGet}
+	}
+	if config.EventName == nil {
+		config.EventName = func(r *http.Request) string {
+			return DefaultPageViewEvent
+		}
+	}
+	if config.NewVisitorTimeout <= 0 {
+		config.NewVisitorTimeout = 2 * time.Second
+	}
+	if config.CookieMaxAge <= 0 {
+		config.CookieMaxAge = 365 * 24 * time.Hour
+	}
+	pv := &pageView{sender: sender, dispatcher: dispatcher, config: config}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !pv.match(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			opts := pv.config.Tracker
+			opts.Event = pv.config.EventName(r)
+			tracker, contactID := NewTrackerFromRequest(r, &opts)
+			event := &Event{ContactID: contactID, Tracker: tracker}
+
+			if len(contactID) == 0 && pv.config.IdentifyNewVisitors {
+				contactID = pv.identify(r, event)
+			} else {
+				pv.sendAsync(r, event)
+			}
+
+			if len(contactID) > 0 {
+				pv.setCookie(w, contactID)
+				r = r.WithContext(ContextWithContactID(r.Context(), contactID))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type pageView struct {
+	sender     EventSender
+	dispatcher *EventDispatcher
+	config     PageViewConfig
+}
+
+func (pv *pageView) match(r *http.Request) bool {
+	methodMatched := false
+	for _, method := range pv.config.Methods {
+		if strings.EqualFold(method, r.Method) {
+			methodMatched = true
+			break
+		}
+	}
+	if !methodMatched {
+		return false
+	}
+	p := r.URL.Path
+	if matchPath(pv.config.Exclude, p) {
+		return false
+	}
+	return len(pv.config.Include) == 0 || matchPath(pv.config.Include, p)
+}
+
+func matchPath(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "/") && strings.HasPrefix(p, pattern) {
+			return true
+		}
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// identify send event of new visitor and return contact id assigned by PAM
+func (pv *pageView) identify(r *http.Request, event *Event) string {
+	ctx, cancel := context.WithTimeout(r.Context(), pv.config.NewVisitorTimeout)
+	defer cancel()
+	res, _, err := pv.sender.SendEventTransactionCtx(ctx, event.ContactID, event.CampaignID, event.TransactionID, event.Tracker)
+	if err != nil {
+		pv.error(r, err)
+		return ""
+	}
+	return res.ContactID
+}
+
+func (pv *pageView) sendAsync(r *http.Request, event *Event) {
+	if pv.dispatcher != nil {
+		if err := pv.dispatcher.TryDispatch(event); err != nil {
+			pv.error(r, err)
+		}
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), pv.config.NewVisitorTimeout)
+		defer cancel()
+		if _, _, err := pv.sender.SendEventTransactionCtx(ctx, event.ContactID, event.CampaignID, event.TransactionID, event.Tracker); err != nil {
+			pv.error(r, err)
+		}
+	}()
+}
+
+func (pv *pageView) setCookie(w http.ResponseWriter, contactID string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     ContactIDCookie,
+		Value:    contactID,
+		Path:     "/",
+		Domain:   pv.config.CookieDomain,
+		MaxAge:   int(pv.config.CookieMaxAge / time.Second),
+		Expires:  time.Now().Add(pv.config.CookieMaxAge),
+		Secure:   pv.config.CookieSecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (pv *pageView) error(r *http.Request, err error) {
+	if pv.config.OnError != nil {
+		pv.config.OnError(r, err)
+	}
+}
@@ -0,0 +1,196 @@
+package pam4sdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type PageViewTestSuite struct {
+	suite.Suite
+}
+
+func TestPageViewTestSuite(t *testing.T) {
+	suite.Run(t, new(PageViewTestSuite))
+}
+
+type senderFunc func(ctx context.Context, contactID string, campaignID string, transactionID string, tracker *Tracker) (*EventResult, string, error)
+
+func (f senderFunc) SendEventTransactionCtx(ctx context.Context, contactID string, campaignID string, transactionID string, tracker *Tracker) (*EventResult, string, error) {
+	return f(ctx, contactID, campaignID, transactionID, tracker)
+}
+
+// serve run request through middleware and return response with contact id seen by next handler
+func (ts *PageViewTestSuite) serve(mw func(http.Handler) http.Handler, r *http.Request) (*http.Response, string) {
+	seen := ""
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = ContactIDFromContext(r.Context())
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec.Result(), seen
+}
+
+func (ts *PageViewTestSuite) contactCookie(res *http.Response) *http.Cookie {
+	for _, c := range res.Cookies() {
+		if c.Name == ContactIDCookie {
+			return c
+		}
+	}
+	return nil
+}
+
+func (ts *PageViewTestSuite) TestPageViewMiddleware_GivenIdentifyNewVisitors_ExpectContactIDFromPAMSetAsCookie() {
+	is := assert.New(ts.T())
+	var sent []*Tracker
+	sender := senderFunc(func(ctx context.Context, contactID string, campaignID string, transactionID string, tracker *Tracker) (*EventResult, string, error) {
+		is.Empty(contactID)
+		sent = append(sent, tracker)
+		return &EventResult{ContactID: "contact_new"}, `{"contact_id":"contact_new"}`, nil
+	})
+	mw := PageViewMiddleware(sender, nil, PageViewConfig{IdentifyNewVisitors: true})
+
+	res, seen := ts.serve(mw, httptest.NewRequest("GET", "http://shop.example.com/products/1", nil))
+	is.Equal("contact_new", seen)
+	if c := ts.contactCookie(res); is.NotNil(c) {
+		is.Equal("contact_new", c.Value)
+		is.Equal("/", c.Path)
+		is.Equal(365*24*3600, c.MaxAge)
+	}
+	if is.Len(sent, 1) {
+		is.Equal(DefaultPageViewEvent, sent[0].Event)
+		is.Equal("http://shop.example.com/products/1", sent[0].PageURL)
+	}
+}
+
+func (ts *PageViewTestSuite) TestPageViewMiddleware_GivenNewVisitorByDefault_ExpectEventDispatchedWithoutWaiting() {
+	is := assert.New(ts.T())
+	release := make(chan struct{})
+	sender := senderFunc(func(ctx context.Context, contactID string, campaignID string, transactionID string, tracker *Tracker) (*EventResult, string, error) {
+		<-release
+		return &EventResult{ContactID: "contact_new"}, "{}", nil
+	})
+	dispatcher := NewEventDispatcher(sender, EventDispatcherConfig{FlushInterval: time.Hour})
+	mw := PageViewMiddleware(sender, dispatcher, PageViewConfig{})
+
+	done := make(chan struct{})
+	var res *http.Response
+	var seen string
+	go func() {
+		res, seen = ts.serve(mw, httptest.NewRequest("GET", "http://shop.example.com/products/1", nil))
+		close(done)
+	}()
+	select {
+	case <-done:
+		is.Empty(seen)
+		is.Nil(ts.contactCookie(res))
+	case <-time.After(time.Second):
+		is.Fail("page view of new visitor waited for PAM")
+	}
+	is.Equal(1, dispatcher.Len())
+
+	close(release)
+	is.NoError(dispatcher.Close(context.Background()))
+}
+
+func (ts *PageViewTestSuite) TestPageViewMiddleware_GivenKnownVisitor_ExpectEventDispatchedAndCookieRefreshed() {
+	is := assert.New(ts.T())
+	mu := sync.Mutex{}
+	var contacts []string
+	sender := senderFunc(func(ctx context.Context, contactID string, campaignID string, transactionID string, tracker *Tracker) (*EventResult, string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		contacts = append(contacts, contactID)
+		return &EventResult{ContactID: contactID}, "{}", nil
+	})
+	dispatcher := NewEventDispatcher(sender, EventDispatcherConfig{FlushInterval: time.Hour})
+	mw := PageViewMiddleware(sender, dispatcher, PageViewConfig{
+		EventName: func(r *http.Request) string {
+			return "view:" + r.URL.Path
+		},
+	})
+
+	r := httptest.NewRequest("GET", "http://shop.example.com/cart", nil)
+	r.AddCookie(&http.Cookie{Name: ContactIDCookie, Value: "contact_123"})
+	res, seen := ts.serve(mw, r)
+	is.Equal("contact_123", seen)
+	if c := ts.contactCookie(res); is.NotNil(c) {
+		is.Equal("contact_123", c.Value)
+	}
+
+	is.Equal(1, dispatcher.Len())
+	is.NoError(dispatcher.Close(context.Background()))
+	is.Equal([]string{"contact_123"}, contacts)
+}
+
+func (ts *PageViewTestSuite) TestPageViewMiddleware_GivenFullBlockingQueue_ExpectResponseNotBlocked() {
+	is := assert.New(ts.T())
+	release := make(chan struct{})
+	sender := senderFunc(func(ctx context.Context, contactID string, campaignID string, transactionID string, tracker *Tracker) (*EventResult, string, error) {
+		<-release
+		return &EventResult{ContactID: contactID}, "{}", nil
+	})
+	dispatcher := NewEventDispatcher(sender, EventDispatcherConfig{BufferSize: 1, BatchSize: 10, FlushInterval: time.Hour})
+	is.NoError(dispatcher.Dispatch(context.Background(), &Event{ContactID: "contact_1", Tracker: &Tracker{Event: "queued"}}))
+	var errs []error
+	mw := PageViewMiddleware(sender, dispatcher, PageViewConfig{
+		OnError: func(r *http.Request, err error) {
+			errs = append(errs, err)
+		},
+	})
+
+	r := httptest.NewRequest("GET", "http://shop.example.com/cart", nil)
+	r.AddCookie(&http.Cookie{Name: ContactIDCookie, Value: "contact_123"})
+	done := make(chan struct{})
+	go func() {
+		ts.serve(mw, r)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		is.Fail("page view waited for full queue")
+	}
+	is.Equal([]error{ErrEventDropped}, errs)
+
+	close(release)
+	is.NoError(dispatcher.Close(context.Background()))
+}
+
+func (ts *PageViewTestSuite) TestPageViewMiddleware_GivenPathRules_ExpectOnlyMatchedRequestsTracked() {
+	is := assert.New(ts.T())
+	var tracked []string
+	sender := senderFunc(func(ctx context.Context, contactID string, campaignID string, transactionID string, tracker *Tracker) (*EventResult, string, error) {
+		tracked = append(tracked, tracker.PageURL)
+		return &EventResult{ContactID: contactID}, "{}", nil
+	})
+	mw := PageViewMiddleware(sender, nil, PageViewConfig{
+		Include:             []string{"/products/*", "/blog/"},
+		Exclude:             []string{"/blog/drafts/"},
+		IdentifyNewVisitors: true,
+	})
+
+	for _, req := range []struct {
+		method string
+		path   string
+	}{
+		{"GET", "/products/1"},
+		{"GET", "/products/1/reviews"},
+		{"GET", "/blog/2024/hello"},
+		{"GET", "/blog/drafts/secret"},
+		{"POST", "/products/2"},
+		{"GET", "/cart"},
+	} {
+		ts.serve(mw, httptest.NewRequest(req.method, "http://shop.example.com"+req.path, nil))
+	}
+	is.Equal([]string{
+		"http://shop.example.com/products/1",
+		"http://shop.example.com/blog/2024/hello",
+	}, tracked)
+}
@@ -0,0 +1,158 @@
+package pam4sdk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PIITransform return value sent to PAM instead of value, false drop the field
+type PIITransform func(value string) (string, bool)
+
+// PIIRules are transforms keyed by field name, they are applied to:
+//
+//   - Tracker.FormFields, nested fields are named "parent.child" and elements of slices are named
+//     like the slice, so "items.email" match email of every item
+//   - Contact sent by UpdateContactAttr and CreateContactWithBody, keyed by JSON name
+//     such as "email", "mobile" and "attrs.mobile"
+//   - media value of AppNotifications, keyed by media alias such as "email"
+//
+// Dropped contact fields are left out of the request body
+type PIIRules map[string]PIITransform
+
+// HashPII return transform sending hex SHA-256 of salt and normalized value, nil normalize keep value as is.
+// Empty value is sent empty so missing data does not become a hash shared by every contact
+func HashPII(salt string, normalize func(string) string) PIITransform {
+	return func(value string) (string, bool) {
+		if normalize != nil {
+			value = normalize(value)
+		}
+		if len(value) == 0 {
+			return "", true
+		}
+		sum := sha256.Sum256([]byte(salt + value))
+		return hex.EncodeToString(sum[:]), true
+	}
+}
+
+// NormalizePII return transform sending normalized value
+func NormalizePII(normalize func(string) string) PIITransform {
+	return func(value string) (string, bool) {
+		return normalize(value), true
+	}
+}
+
+// DropPII return transform removing the field
+func DropPII() PIITransform {
+	return func(value string) (string, bool) {
+		return "", false
+	}
+}
+
+// NormalizeEmail trim spaces and lower case email
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizePhone return normalizer converting phone number to E.164 such as +66812345678,
+// local number starting with 0 get countryCode
+func NormalizePhone(countryCode string) func(string) string {
+	countryCode = strings.TrimPrefix(countryCode, "+")
+	return func(phone string) string {
+		phone = strings.TrimSpace(phone)
+		international := strings.HasPrefix(phone, "+") || strings.HasPrefix(phone, "00")
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, phone)
+		switch {
+		case len(digits) == 0:
+			return ""
+		case strings.HasPrefix(phone, "00"):
+			digits = digits[2:]
+		case !international && strings.HasPrefix(digits, "0"):
+			digits = countryCode + digits[1:]
+		}
+		return "+" + digits
+	}
+}
+
+// apply return copy of fields decoded from JSON with rules applied, nested maps and slices are copied too
+// so caller data is never modified
+func (rules PIIRules) apply(fields map[string]interface{}, prefix string) map[string]interface{} {
+	if fields == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		if v, keep := rules.applyValue(prefix+key, value); keep {
+			out[key] = v
+		}
+	}
+	return out
+}
+
+// applyValue apply rule of field name to value decoded from JSON, elements of slices are named
+// like the slice so "items.email" match email of every item
+func (rules PIIRules) applyValue(name string, value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return rules.apply(v, name+"."), true
+	case []interface{}:
+		out := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			if item, keep := rules.applyValue(name, item); keep {
+				out = append(out, item)
+			}
+		}
+		return out, true
+	}
+
+	transform, ok := rules[name]
+	if !ok || value == nil {
+		return value, true
+	}
+	switch v := value.(type) {
+	case string:
+		return transform(v)
+	case float64:
+		// Number such as phone is written without exponent
+		return transform(strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	return transform(fmt.Sprint(value))
+}
+
+// value return media value transformed by rule of field, false when it is dropped
+func (rules PIIRules) value(field string, value string) (string, bool) {
+	if transform, ok := rules[field]; ok {
+		return transform(value)
+	}
+	return value, true
+}
+
+// contact return body of contact with rules applied, contact is returned as is when there is no rule
+func (rules PIIRules) contact(contact interface{}) (interface{}, error) {
+	if len(rules) == 0 || contact == nil {
+		return contact, nil
+	}
+	var js []byte
+	switch c := contact.(type) {
+	case string:
+		js = []byte(c)
+	default:
+		var err error
+		if js, err = json.Marshal(c); err != nil {
+			return nil, err
+		}
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(js, &fields); err != nil {
+		return nil, fmt.Errorf("pam cannot apply PII rules to contact which is not JSON object: %s", err.Error())
+	}
+	return rules.apply(fields, ""), nil
+}
@@ -0,0 +1,199 @@
+package pam4sdk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/mock"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type PIITestSuite struct {
+	suite.Suite
+}
+
+func TestPIITestSuite(t *testing.T) {
+	suite.Run(t, new(PIITestSuite))
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func (ts *PIITestSuite) rules() PIIRules {
+	return PIIRules{
+		"email":        HashPII("salt:", NormalizeEmail),
+		"mobile":       HashPII("salt:", NormalizePhone("66")),
+		"attrs.mobile": HashPII("salt:", NormalizePhone("66")),
+		"address":      DropPII(),
+	}
+}
+
+func (ts *PIITestSuite) TestNormalizePhone_GivenFormats_ExpectE164() {
+	is := assert.New(ts.T())
+	normalize := NormalizePhone("+66")
+	is.Equal("+66812345678", normalize("081-234-5678"))
+	is.Equal("+66812345678", normalize("+66 81 234 5678"))
+	is.Equal("+66812345678", normalize("0066812345678"))
+	is.Equal("", normalize(" - "))
+	is.Equal("jane@example.com", NormalizeEmail(" Jane@Example.COM "))
+}
+
+func (ts *PIITestSuite) TestSendEvent_GivenPIIRules_ExpectFormFieldsHashedOrDropped() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(&RequestLogger{rq: mockRq, logger: NewMockLogger()}, nil)
+	sdk.pii = ts.rules()
+
+	body := mock.MatchedBy(func(p map[string]interface{}) bool {
+		fields := p["form_fields"].(map[string]interface{})
+		_, hasAddress := fields["address"]
+		return fields["email"] == sha256Hex("salt:jane@example.com") && !hasAddress && fields["name"] == "Jane"
+	})
+	mockRq.On("PostJSONRHCCtx", mock.Anything, "/trackers/events", body, mock.Anything, mock.Anything).Return(&http.Response{}, "{}", nil)
+
+	tracker := &Tracker{Event: "register", FormFields: map[string]interface{}{
+		"email":   "Jane@Example.com",
+		"address": "Bangkok",
+		"name":    "Jane",
+	}}
+	_, err := sdk.SendEvent("contact_1", "", tracker)
+
+	if is.NoError(err) {
+		mockRq.AssertExpectations(ts.T())
+	}
+	is.Equal("Jane@Example.com", tracker.FormFields["email"])
+}
+
+func (ts *PIITestSuite) TestSendEvent_GivenItemsArrayWithEmail_ExpectEveryItemHashed() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(&RequestLogger{rq: mockRq, logger: NewMockLogger()}, nil)
+	sdk.pii = PIIRules{
+		"items.email": HashPII("salt:", NormalizeEmail),
+		"recipients":  HashPII("salt:", NormalizeEmail),
+	}
+
+	var fields map[string]interface{}
+	body := mock.MatchedBy(func(p map[string]interface{}) bool {
+		fields = p["form_fields"].(map[string]interface{})
+		return true
+	})
+	mockRq.On("PostJSONRHCCtx", mock.Anything, "/trackers/events", body, mock.Anything, mock.Anything).Return(&http.Response{}, "{}", nil)
+
+	items := []map[string]interface{}{
+		{"id": "p1", "email": "Jane@Example.com"},
+		{"id": "p2", "email": "john@example.com"},
+	}
+	tracker := &Tracker{Event: EventPurchase, FormFields: map[string]interface{}{
+		"items":      items,
+		"recipients": []interface{}{"Jane@Example.com"},
+	}}
+	_, err := sdk.SendEvent("contact_1", "", tracker)
+
+	is.NoError(err)
+	is.Equal([]interface{}{
+		map[string]interface{}{"id": "p1", "email": sha256Hex("salt:jane@example.com")},
+		map[string]interface{}{"id": "p2", "email": sha256Hex("salt:john@example.com")},
+	}, fields["items"])
+	is.Equal([]interface{}{sha256Hex("salt:jane@example.com")}, fields["recipients"])
+	is.Equal("Jane@Example.com", items[0]["email"])
+}
+
+func (ts *PIITestSuite) TestSendEvent_GivenTypedMapAndStructFields_ExpectNestedEmailHashed() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(&RequestLogger{rq: mockRq, logger: NewMockLogger()}, nil)
+	sdk.pii = PIIRules{
+		"user.email":      HashPII("salt:", NormalizeEmail),
+		"customer.email":  HashPII("salt:", NormalizeEmail),
+		"customer.mobile": HashPII("salt:", NormalizePhone("66")),
+	}
+
+	var fields map[string]interface{}
+	body := mock.MatchedBy(func(p map[string]interface{}) bool {
+		fields = p["form_fields"].(map[string]interface{})
+		return true
+	})
+	mockRq.On("PostJSONRHCCtx", mock.Anything, "/trackers/events", body, mock.Anything, mock.Anything).Return(&http.Response{}, "{}", nil)
+
+	type customer struct {
+		Email  string `json:"email"`
+		Mobile int    `json:"mobile"`
+	}
+	tracker := &Tracker{Event: "register", FormFields: map[string]interface{}{
+		"user":     map[string]string{"email": "a@b.c"},
+		"customer": customer{Email: "a@b.c", Mobile: 812345678},
+	}}
+	_, err := sdk.SendEvent("contact_1", "", tracker)
+
+	is.NoError(err)
+	is.Equal(map[string]interface{}{"email": sha256Hex("salt:a@b.c")}, fields["user"])
+	is.Equal(map[string]interface{}{
+		"email":  sha256Hex("salt:a@b.c"),
+		"mobile": sha256Hex("salt:+812345678"),
+	}, fields["customer"])
+}
+
+func (ts *PIITestSuite) TestUpdateContactAttr_GivenPIIRules_ExpectContactFieldsHashed() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(&RequestLogger{rq: mockRq, logger: NewMockLogger()}, nil)
+	sdk.pii = ts.rules()
+
+	mobile := sha256Hex("salt:+66812345678")
+	body := mock.MatchedBy(func(p map[string]interface{}) bool {
+		attrs := p["attrs"].(map[string]interface{})
+		return p["email"] == sha256Hex("salt:jane@example.com") && p["mobile"] == mobile &&
+			attrs["mobile"] == mobile && p["firstname"] == "Jane"
+	})
+	mockRq.On("PutJSONCtx", mock.Anything, "/api/contacts/contact_1", body).Return("{}", nil)
+
+	contact := &Contact{Email: "jane@example.com", Mobile: "081-234-5678", Firstname: "Jane"}
+	contact.Attrs.Mobile = "0812345678"
+	_, err := sdk.UpdateContactAttr("contact_1", contact)
+
+	if is.NoError(err) {
+		mockRq.AssertExpectations(ts.T())
+	}
+	is.Equal("jane@example.com", contact.Email)
+}
+
+func (ts *PIITestSuite) TestAppNotificationsCtx_GivenPIIRules_ExpectMediaValueHashed() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(&RequestLogger{rq: mockRq, logger: NewMockLogger()}, nil)
+	sdk.pii = ts.rules()
+
+	p := map[string]string{
+		"contact_id":  "contact_1",
+		"media_alias": "email",
+		"media_value": sha256Hex("salt:jane@example.com"),
+	}
+	mockRq.On("GetCtx", mock.Anything, "/api/app-notifications", p).Return(`{"items":[]}`, nil)
+
+	_, _, err := sdk.AppNotificationsCtx(context.Background(), "contact_1", "email", "JANE@example.com")
+
+	if is.NoError(err) {
+		mockRq.AssertExpectations(ts.T())
+	}
+}
+
+func (ts *PIITestSuite) TestCreateContactWithBody_GivenBodyIsNotJSONObject_ExpectErrorAndNothingSent() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	mockLogger := NewMockLogger()
+	mockLogger.On("ErrorFL", mock.Anything)
+	sdk := NewSdkR(&RequestLogger{rq: mockRq, logger: mockLogger}, nil)
+	sdk.pii = ts.rules()
+
+	_, err := sdk.CreateContactWithBody("email=jane@example.com")
+
+	is.Error(err)
+	mockRq.AssertNotCalled(ts.T(), "PostJSONCtx", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,151 @@
+package pam4sdk
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CampaignQuery is query of FindCampaigns, zero fields are not sent
+type CampaignQuery struct {
+	// Q search campaign name
+	Q       string
+	Aliases []string
+	IDs     []string
+	Page    int
+	Limit   int
+	// Sort is field to sort by, prefix it with "-" for descending order
+	Sort string
+	// Filters are extra query parameters such as state, fields above take precedence
+	Filters map[string]string
+}
+
+func (q *CampaignQuery) params() map[string]string {
+	p := map[string]string{}
+	if q == nil {
+		return p
+	}
+	setParam(p, "q", q.Q)
+	setParam(p, "aliases", strings.Join(q.Aliases, ","))
+	setParam(p, "ids", strings.Join(q.IDs, ","))
+	setPaging(p, q.Page, q.Limit)
+	setParam(p, "sort", q.Sort)
+	setFilters(p, q.Filters)
+	return p
+}
+
+// ContactQuery is query of FindContacts, zero fields are not sent
+type ContactQuery struct {
+	// Q search value of Field
+	Q     string
+	Field string
+	// Tags return only contacts having every tag
+	Tags  []string
+	Page  int
+	Limit int
+	// Sort is field to sort by, prefix it with "-" for descending order
+	Sort string
+	// Filters are extra query parameters, fields above take precedence
+	Filters map[string]string
+}
+
+func (q *ContactQuery) params() map[string]string {
+	p := map[string]string{}
+	if q == nil {
+		return p
+	}
+	setParam(p, "q", q.Q)
+	setParam(p, "field", q.Field)
+	setParam(p, "tags", strings.Join(q.Tags, ","))
+	setPaging(p, q.Page, q.Limit)
+	setParam(p, "sort", q.Sort)
+	setFilters(p, q.Filters)
+	return p
+}
+
+// MediaQuery is query of FindMedia, false and empty fields are not sent
+type MediaQuery struct {
+	// All return media of every type
+	All bool
+	// ExcludeDisabled leave out disabled media
+	ExcludeDisabled bool
+	// Type is media type such as sms
+	Type string
+}
+
+func (q *MediaQuery) params() map[string]string {
+	p := map[string]string{}
+	if q == nil {
+		return p
+	}
+	if q.All {
+		p["is_all"] = "true"
+	}
+	if q.ExcludeDisabled {
+		p["exclude_disabled"] = "true"
+	}
+	setParam(p, "type", q.Type)
+	return p
+}
+
+func setParam(p map[string]string, key string, value string) {
+	if len(value) > 0 {
+		p[key] = value
+	}
+}
+
+func setPaging(p map[string]string, page int, limit int) {
+	if page > 0 {
+		p["page"] = strconv.Itoa(page)
+	}
+	if limit > 0 {
+		p["limit"] = strconv.Itoa(limit)
+	}
+}
+
+func setFilters(p map[string]string, filters map[string]string) {
+	for key, value := range filters {
+		if _, ok := p[key]; !ok {
+			setParam(p, key, value)
+		}
+	}
+}
+
+// splitList split comma separated list of legacy string parameters
+func splitList(s string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// atoi parse number of legacy string parameters, invalid number is treated as unset
+func atoi(s string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(s))
+	return n
+}
+
+// parseBool parse flag of legacy string parameters, invalid flag is treated as false
+func parseBool(s string) bool {
+	b, _ := strconv.ParseBool(strings.TrimSpace(s))
+	return b
+}
+
+// campaignQuery return query of legacy GetCampaigns arguments
+func campaignQuery(q, aliases string, ids []string, page, limit string) *CampaignQuery {
+	return &CampaignQuery{Q: q, Aliases: splitList(aliases), IDs: ids, Page: atoi(page), Limit: atoi(limit)}
+}
+
+// contactQuery return query of legacy GetContacts arguments
+func contactQuery(q, field, page, limit string) *ContactQuery {
+	return &ContactQuery{Q: q, Field: field, Page: atoi(page), Limit: atoi(limit)}
+}
+
+// contactTagsQuery return query of legacy GetContactsTags arguments
+func contactTagsQuery(tags, q, page, limit string) *ContactQuery {
+	return &ContactQuery{Q: q, Tags: splitList(tags), Page: atoi(page), Limit: atoi(limit)}
+}
+
+// mediaQuery return query of legacy GetMedia arguments
+func mediaQuery(isAll, isExcludeDisabled, mediaType string) *MediaQuery {
+	return &MediaQuery{All: parseBool(isAll), ExcludeDisabled: parseBool(isExcludeDisabled), Type: mediaType}
+}
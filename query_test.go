@@ -0,0 +1,97 @@
+package pam4sdk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/mock"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type QueryTestSuite struct {
+	suite.Suite
+}
+
+func TestQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(QueryTestSuite))
+}
+
+func (ts *QueryTestSuite) TestFindCampaignsCtx_GivenQuery_ExpectOnlySetParamsSent() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(nil, &RequestLogger{rq: mockRq, logger: NewMockLogger()})
+
+	p := map[string]string{
+		"q":       "sale",
+		"aliases": "summer,winter",
+		"limit":   "20",
+		"sort":    "-created_at",
+		"state":   "running",
+	}
+	mockRq.On("GetCtx", mock.Anything, "/campaigns", p).Return(`{"page":1,"data":[]}`, nil)
+
+	_, _, err := sdk.FindCampaignsCtx(context.Background(), &CampaignQuery{
+		Q:       "sale",
+		Aliases: []string{"summer", "winter"},
+		Limit:   20,
+		Sort:    "-created_at",
+		Filters: map[string]string{"state": "running", "q": "ignored", "empty": ""},
+	})
+
+	if is.NoError(err) {
+		mockRq.AssertExpectations(ts.T())
+	}
+}
+
+func (ts *QueryTestSuite) TestGetContacts_GivenEmptyKeywordAndField_ExpectNotSent() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(&RequestLogger{rq: mockRq, logger: NewMockLogger()}, nil)
+
+	mockRq.On("GetCtx", mock.Anything, "/api/contacts", map[string]string{"page": "2"}).Return(`{}`, nil)
+
+	_, err := sdk.GetContacts("", "", "2", "")
+	is.NoError(err)
+	mockRq.AssertExpectations(ts.T())
+}
+
+func (ts *QueryTestSuite) TestGetContactsTags_GivenNoTags_ExpectTagEndpointRequested() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(&RequestLogger{rq: mockRq, logger: NewMockLogger()}, nil)
+
+	mockRq.On("GetCtx", mock.Anything, "/api/contacts/tag/multiple", map[string]string{"q": "jane", "limit": "10"}).Return(`{}`, nil)
+
+	_, err := sdk.GetContactsTags("", "jane", "", "10")
+	is.NoError(err)
+	mockRq.AssertExpectations(ts.T())
+}
+
+func (ts *QueryTestSuite) TestGetCampaigns_GivenLegacyArguments_ExpectSameParamsAsFindCampaigns() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(nil, &RequestLogger{rq: mockRq, logger: NewMockLogger()})
+
+	p := map[string]string{"aliases": "summer,winter", "ids": "c1,c2", "page": "3"}
+	mockRq.On("GetCtx", mock.Anything, "/campaigns", p).Return(`{"page":1,"data":[]}`, nil).Twice()
+
+	_, err := sdk.GetCampaigns("", "summer,winter", []string{"c1", "c2"}, "3", "x")
+	is.NoError(err)
+	_, err = sdk.FindCampaigns(&CampaignQuery{Aliases: []string{"summer", "winter"}, IDs: []string{"c1", "c2"}, Page: 3})
+	is.NoError(err)
+	mockRq.AssertExpectations(ts.T())
+}
+
+func (ts *QueryTestSuite) TestGetMedia_GivenFalseFlags_ExpectNotSent() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(nil, &RequestLogger{rq: mockRq, logger: NewMockLogger()})
+
+	p := map[string]string{"exclude_disabled": "true", "type": "sms"}
+	mockRq.On("GetCtx", mock.Anything, "/media", p).Return(`[]`, nil)
+
+	_, err := sdk.GetMedia("false", "true", "sms")
+	is.NoError(err)
+	mockRq.AssertExpectations(ts.T())
+}
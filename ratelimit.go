@@ -0,0 +1,141 @@
+package pam4sdk
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter is token bucket which limit number of requests per second, it is safe for concurrent use.
+// Adaptive limiter halve its rate when PAM respond 429 and slowly recover on successful responses
+type RateLimiter struct {
+	mu          sync.Mutex
+	rate        float64
+	maxRate     float64
+	minRate     float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+	maxPause    time.Duration
+	adaptive    bool
+	now         func() time.Time
+}
+
+// NewRateLimiter return limiter allowing rate requests per second with burst
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	l := &RateLimiter{
+		rate:     rate,
+		maxRate:  rate,
+		minRate:  rate,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		maxPause: DefaultMaxRetryAfter,
+		now:      time.Now,
+	}
+	l.last = l.now()
+	return l
+}
+
+// NewAdaptiveRateLimiter return limiter starting at rate which slow down to minRate when PAM respond 429
+func NewAdaptiveRateLimiter(rate float64, burst int, minRate float64) *RateLimiter {
+	l := NewRateLimiter(rate, burst)
+	if minRate <= 0 || minRate > rate {
+		minRate = rate / 10
+	}
+	l.minRate = minRate
+	l.adaptive = true
+	return l
+}
+
+// SetMaxRetryAfter set longest pause of adaptive limiter asked by Retry-After of 429 response,
+// longer Retry-After is clamped to it. Default is DefaultMaxRetryAfter, zero is no limit
+func (l *RateLimiter) SetMaxRetryAfter(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxPause = d
+}
+
+// Rate return current requests per second
+func (l *RateLimiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// Wait block until a request is allowed or ctx is done, limiter with rate <= 0 never block
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	now := l.now()
+	l.refill(now)
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 && l.rate > 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	if pause := l.pausedUntil.Sub(now); pause > wait {
+		wait = pause
+	}
+	l.mu.Unlock()
+
+	if err := sleepCtx(ctx, wait); err != nil {
+		// Give back the token reserved for this request
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// observe adapt the rate from PAM response, it do nothing when limiter is not adaptive
+func (l *RateLimiter) observe(res *http.Response) {
+	if !l.adaptive || res == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(l.now())
+	if res.StatusCode == http.StatusTooManyRequests {
+		l.rate = l.rate / 2
+		if l.rate < l.minRate {
+			l.rate = l.minRate
+		}
+		if wait, ok := parseRetryAfter(res.Header.Get("Retry-After"), l.now()); ok {
+			if l.maxPause > 0 && wait > l.maxPause {
+				wait = l.maxPause
+			}
+			if until := l.now().Add(wait); until.After(l.pausedUntil) {
+				l.pausedUntil = until
+			}
+		}
+		return
+	}
+	if res.StatusCode < 400 && l.rate < l.maxRate {
+		l.rate += l.maxRate / 20
+		if l.rate > l.maxRate {
+			l.rate = l.maxRate
+		}
+	}
+}
+
+func (l *RateLimiter) refill(now time.Time) {
+	elapsed := now.Sub(l.last).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	l.last = now
+	l.tokens += elapsed * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
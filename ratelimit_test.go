@@ -0,0 +1,107 @@
+package pam4sdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type RateLimiterTestSuite struct {
+	suite.Suite
+}
+
+func TestRateLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(RateLimiterTestSuite))
+}
+
+func (ts *RateLimiterTestSuite) TestWait_GivenBurst_ExpectBurstAllowedImmediately() {
+	is := assert.New(ts.T())
+	l := NewRateLimiter(1, 3)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		is.NoError(l.Wait(context.Background()))
+	}
+	is.True(time.Since(start) < 100*time.Millisecond)
+}
+
+func (ts *RateLimiterTestSuite) TestWait_GivenEmptyBucket_ExpectWaitForRefill() {
+	is := assert.New(ts.T())
+	l := NewRateLimiter(20, 1)
+
+	start := time.Now()
+	is.NoError(l.Wait(context.Background()))
+	is.NoError(l.Wait(context.Background()))
+	is.True(time.Since(start) >= 40*time.Millisecond)
+}
+
+func (ts *RateLimiterTestSuite) TestWait_GivenCancelledContext_ExpectContextError() {
+	is := assert.New(ts.T())
+	l := NewRateLimiter(0.1, 1)
+	is.NoError(l.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	is.Equal(context.DeadlineExceeded, l.Wait(ctx))
+}
+
+func (ts *RateLimiterTestSuite) TestObserve_GivenAdaptiveAnd429_ExpectRateDecreasedThenRecovered() {
+	is := assert.New(ts.T())
+	l := NewAdaptiveRateLimiter(100, 10, 10)
+
+	l.observe(&http.Response{StatusCode: http.StatusTooManyRequests})
+	is.Equal(50.0, l.Rate())
+	l.observe(&http.Response{StatusCode: http.StatusTooManyRequests})
+	l.observe(&http.Response{StatusCode: http.StatusTooManyRequests})
+	l.observe(&http.Response{StatusCode: http.StatusTooManyRequests})
+	is.Equal(10.0, l.Rate())
+
+	l.observe(&http.Response{StatusCode: http.StatusOK})
+	is.Equal(15.0, l.Rate())
+}
+
+func (ts *RateLimiterTestSuite) TestObserve_GivenLongRetryAfter_ExpectPauseClamped() {
+	is := assert.New(ts.T())
+	now := time.Date(2020, 7, 24, 8, 0, 0, 0, time.UTC)
+	l := NewAdaptiveRateLimiter(100, 10, 10)
+	l.now = func() time.Time { return now }
+
+	l.observe(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"86400"}}})
+	is.Equal(now.Add(DefaultMaxRetryAfter), l.pausedUntil)
+
+	l.SetMaxRetryAfter(time.Minute)
+	farFuture := now.AddDate(1, 0, 0).Format(http.TimeFormat)
+	l.observe(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {farFuture}}})
+	is.Equal(now.Add(time.Minute), l.pausedUntil)
+}
+
+func (ts *RateLimiterTestSuite) TestObserve_GivenNotAdaptive_ExpectRateUnchanged() {
+	is := assert.New(ts.T())
+	l := NewRateLimiter(100, 10)
+
+	l.observe(&http.Response{StatusCode: http.StatusTooManyRequests})
+	is.Equal(100.0, l.Rate())
+}
+
+func (ts *RateLimiterTestSuite) TestRequester_GivenRateLimiter_ExpectRequestsThrottled() {
+	is := assert.New(ts.T())
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	cfg := NewCustomRequesterConfig(server.URL, "x-app-id", "x-secret", "app-id", "secret", time.Second)
+	rqt := NewRequester(cfg, NewLoggerSimple(), RequesterRateLimiter(NewRateLimiter(20, 1)))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := rqt.Get("/abc", nil)
+		is.NoError(err)
+	}
+	is.True(time.Since(start) >= 80*time.Millisecond)
+}
@@ -0,0 +1,339 @@
+package pam4sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeAction is what ApplySegmentPlan do with a segment
+type ChangeAction string
+
+// Change actions
+const (
+	ChangeCreate ChangeAction = "create"
+	ChangeUpdate ChangeAction = "update"
+	ChangeDelete ChangeAction = "delete"
+	ChangeNoop   ChangeAction = "no-op"
+)
+
+// ReconcileOptions control PlanSegments and ApplySegmentPlan
+type ReconcileOptions struct {
+	// Prune delete segments which have alias but no definition, they are left alone by default
+	Prune bool
+	// Protect are alias patterns of path.Match which are never deleted by Prune
+	Protect []string
+	// MaxDeletes abort apply before any change, also on dry run, when plan delete more segments,
+	// zero use DefaultMaxDeletes and negative is no limit
+	MaxDeletes int
+	// DryRun compute plan without changing PAM
+	DryRun bool
+}
+
+// DefaultMaxDeletes is limit of deleted segments when ReconcileOptions.MaxDeletes is zero
+const DefaultMaxDeletes = 5
+
+// validate return error for malformed Protect pattern and for Prune without definitions,
+// which would delete every segment having alias
+func (o *ReconcileOptions) validate(defs []*SegmentDefinition) error {
+	for _, pattern := range o.Protect {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("pam segment protect pattern %q: %w", pattern, err)
+		}
+	}
+	if o.Prune && len(defs) == 0 {
+		return NewErrM("pam segment prune without definitions would delete every segment")
+	}
+	return nil
+}
+
+func (o *ReconcileOptions) maxDeletes() int {
+	if o.MaxDeletes == 0 {
+		return DefaultMaxDeletes
+	}
+	return o.MaxDeletes
+}
+
+func (o *ReconcileOptions) protected(alias string) bool {
+	for _, pattern := range o.Protect {
+		if ok, _ := path.Match(pattern, alias); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldDiff is changed field of segment, values are written in human readable form
+// and triggers are written in segment expression when possible
+type FieldDiff struct {
+	Field   string
+	Current string
+	Desired string
+}
+
+// SegmentChange is planned change of one segment
+type SegmentChange struct {
+	Action ChangeAction
+	Alias  string
+	// SegmentID is empty for segment to create until it is applied
+	SegmentID string
+	// Desired is body sent by create and update
+	Desired *Segment
+	Diffs   []FieldDiff
+	// Reason explain no-op of segment which is not changed on purpose
+	Reason  string
+	Applied bool
+}
+
+// SegmentPlan is list of changes ordered by alias
+type SegmentPlan struct {
+	Changes []*SegmentChange
+}
+
+// Count return number of changes with action
+func (p *SegmentPlan) Count(action ChangeAction) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// HasChanges return true when applying plan change PAM
+func (p *SegmentPlan) HasChanges() bool {
+	return p.Count(ChangeNoop) < len(p.Changes)
+}
+
+// String return plan in human readable form
+func (p *SegmentPlan) String() string {
+	var b strings.Builder
+	symbols := map[ChangeAction]string{ChangeCreate: "+", ChangeUpdate: "~", ChangeDelete: "-", ChangeNoop: "="}
+	for _, c := range p.Changes {
+		fmt.Fprintf(&b, "%s %s %s", symbols[c.Action], c.Action, c.Alias)
+		if len(c.SegmentID) > 0 {
+			fmt.Fprintf(&b, " (%s)", c.SegmentID)
+		}
+		if len(c.Reason) > 0 {
+			fmt.Fprintf(&b, ": %s", c.Reason)
+		}
+		b.WriteString("\n")
+		for _, d := range c.Diffs {
+			fmt.Fprintf(&b, "    %s: %q => %q\n", d.Field, d.Current, d.Desired)
+		}
+	}
+	fmt.Fprintf(&b, "Plan: %d to create, %d to update, %d to delete.\n",
+		p.Count(ChangeCreate), p.Count(ChangeUpdate), p.Count(ChangeDelete))
+	return b.String()
+}
+
+// PlanSegments compare definitions with segments in PAM, segments are listed with GetSegments
+// and those with definition are fetched with GetSegmentByID. opts may be nil
+func (sdk *Sdk) PlanSegments(ctx context.Context, defs []*SegmentDefinition, opts *ReconcileOptions) (*SegmentPlan, error) {
+	if opts == nil {
+		opts = &ReconcileOptions{}
+	}
+	if err := opts.validate(defs); err != nil {
+		return nil, err
+	}
+	desired := map[string]*Segment{}
+	defined := map[string]*SegmentDefinition{}
+	for _, def := range defs {
+		s, err := def.Segment()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := desired[s.Alias]; ok {
+			return nil, def.error("alias is defined more than once")
+		}
+		desired[s.Alias] = s
+		defined[s.Alias] = def
+	}
+
+	current := map[string]*SegmentResponse{}
+	it := sdk.IterateSegments(ctx, "", nil)
+	defer it.Close()
+	for it.Next() {
+		s := it.Item()
+		if len(s.Alias) == 0 {
+			continue
+		}
+		if other, ok := current[s.Alias]; ok {
+			return nil, NewErrM(fmt.Sprintf("pam segment alias %s is used by segments %s and %s", s.Alias, other.ID, s.ID))
+		}
+		current[s.Alias] = s
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	plan := &SegmentPlan{}
+	for alias, want := range desired {
+		have, ok := current[alias]
+		if !ok {
+			plan.Changes = append(plan.Changes, &SegmentChange{
+				Action:  ChangeCreate,
+				Alias:   alias,
+				Desired: want,
+				Diffs:   diffSegment(&Segment{}, want),
+			})
+			continue
+		}
+		detail, _, err := sdk.GetSegmentByIDCtx(ctx, have.ID)
+		if err != nil {
+			return nil, err
+		}
+		if defined[alias].IsEnabled == nil {
+			// update body must carry is_enabled, so it keep the current value
+			want.IsEnabled = detail.IsEnabled
+		}
+		change := &SegmentChange{
+			Action:    ChangeUpdate,
+			Alias:     alias,
+			SegmentID: have.ID,
+			Desired:   want,
+			Diffs:     diffSegment(&detail.Segment, want),
+		}
+		if len(change.Diffs) == 0 {
+			change.Action = ChangeNoop
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+	if opts.Prune {
+		for alias, have := range current {
+			if _, ok := desired[alias]; ok {
+				continue
+			}
+			change := &SegmentChange{Action: ChangeDelete, Alias: alias, SegmentID: have.ID}
+			if opts.protected(alias) {
+				change.Action = ChangeNoop
+				change.Reason = "protected from prune"
+			}
+			plan.Changes = append(plan.Changes, change)
+		}
+	}
+	sort.Slice(plan.Changes, func(i, j int) bool {
+		return plan.Changes[i].Alias < plan.Changes[j].Alias
+	})
+	return plan, nil
+}
+
+// ApplySegmentPlan create and update segments then delete segments of plan, it stop at the first
+// error and mark changes done before it as Applied. opts may be nil
+func (sdk *Sdk) ApplySegmentPlan(ctx context.Context, plan *SegmentPlan, opts *ReconcileOptions) error {
+	if opts == nil {
+		opts = &ReconcileOptions{}
+	}
+	if deletes, limit := plan.Count(ChangeDelete), opts.maxDeletes(); limit > 0 && deletes > limit {
+		return NewErrM(fmt.Sprintf("pam segment plan delete %d segments, more than limit %d", deletes, limit))
+	}
+	if opts.DryRun {
+		return nil
+	}
+	for _, action := range []ChangeAction{ChangeCreate, ChangeUpdate, ChangeDelete} {
+		for _, c := range plan.Changes {
+			if c.Action != action || c.Applied {
+				continue
+			}
+			if err := sdk.applySegmentChange(ctx, c); err != nil {
+				return fmt.Errorf("pam %s segment %s: %w", c.Action, c.Alias, err)
+			}
+			c.Applied = true
+		}
+	}
+	return nil
+}
+
+// ReconcileSegments plan and apply definitions, the plan is returned also on dry run and error
+func (sdk *Sdk) ReconcileSegments(ctx context.Context, defs []*SegmentDefinition, opts *ReconcileOptions) (*SegmentPlan, error) {
+	plan, err := sdk.PlanSegments(ctx, defs, opts)
+	if err != nil {
+		return nil, err
+	}
+	return plan, sdk.ApplySegmentPlan(ctx, plan, opts)
+}
+
+func (sdk *Sdk) applySegmentChange(ctx context.Context, c *SegmentChange) error {
+	switch c.Action {
+	case ChangeCreate:
+		res, _, err := sdk.CreateSegmentCtx(ctx, c.Desired)
+		if err != nil {
+			return err
+		}
+		c.SegmentID = res.ID
+	case ChangeUpdate:
+		_, _, err := sdk.UpdateSegmentCtx(ctx, c.SegmentID, c.Desired)
+		return err
+	case ChangeDelete:
+		_, _, err := sdk.DeleteSegmentCtx(ctx, c.SegmentID)
+		return err
+	}
+	return nil
+}
+
+// diffSegment return fields of desired which differ from current
+func diffSegment(current *Segment, desired *Segment) []FieldDiff {
+	var diffs []FieldDiff
+	add := func(field string, have string, want string) {
+		if have != want {
+			diffs = append(diffs, FieldDiff{Field: field, Current: have, Desired: want})
+		}
+	}
+	add("name", current.Name, desired.Name)
+	add("description", current.Description, desired.Description)
+	add("is_enabled", strconv.FormatBool(current.IsEnabled), strconv.FormatBool(desired.IsEnabled))
+	if !sameTriggers(current.Triggers, desired.Triggers) {
+		diffs = append(diffs, FieldDiff{Field: "triggers", Current: triggersText(current.Triggers), Desired: triggersText(desired.Triggers)})
+	}
+	add("trigger_excludes", strings.Join(current.TriggerExcludes, ", "), strings.Join(desired.TriggerExcludes, ", "))
+	add("delay_amount", current.DelayAmount, desired.DelayAmount)
+	add("delay_unit", current.DelayUnit, desired.DelayUnit)
+	return diffs
+}
+
+// sameTriggers return true when triggers are equal as JSON or, ignoring condition fields which
+// the SDK does not know, are written in the same segment expression
+func sameTriggers(current []*SegmentTrigger, desired []*SegmentTrigger) bool {
+	if len(current) == 0 || len(desired) == 0 {
+		return len(current) == len(desired)
+	}
+	if sameJSON(current, desired) {
+		return true
+	}
+	have, err := looseExpression(current)
+	if err != nil {
+		return false
+	}
+	want, err := looseExpression(desired)
+	return err == nil && have == want
+}
+
+func looseExpression(triggers []*SegmentTrigger) (string, error) {
+	c, err := parseSegmentTriggers(triggers, false)
+	if err != nil {
+		return "", err
+	}
+	return FormatCondition(c)
+}
+
+// triggersText return triggers in segment expression, triggers which the expression cannot
+// represent are written as JSON so they are still compared
+func triggersText(triggers []*SegmentTrigger) string {
+	if len(triggers) == 0 {
+		return ""
+	}
+	s := &Segment{Triggers: triggers}
+	if expression, err := s.Expression(); err == nil {
+		return expression
+	}
+	js, err := json.Marshal(triggers)
+	if err != nil {
+		return fmt.Sprintf("%v", triggers)
+	}
+	return string(js)
+}
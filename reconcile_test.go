@@ -0,0 +1,229 @@
+package pam4sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/mock"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type ReconcileTestSuite struct {
+	suite.Suite
+	mockRq *MockRequester
+	sdk    *Sdk
+}
+
+func TestReconcileTestSuite(t *testing.T) {
+	suite.Run(t, new(ReconcileTestSuite))
+}
+
+func (ts *ReconcileTestSuite) SetupTest() {
+	ts.mockRq = NewMockRequester()
+	ts.sdk = NewSdkR(nil, &RequestLogger{rq: ts.mockRq, logger: NewMockLogger()})
+	ts.mockRq.On("GetCtx", mock.Anything, "/triggers", mock.Anything).Return(`{"page":1,"limit":50,"data":[
+		{"id":"seg_1","alias":"loyal","name":"Loyal"},
+		{"id":"seg_2","alias":"same","name":"Same"},
+		{"id":"seg_3","alias":"old","name":"Old"},
+		{"id":"seg_4","alias":"system-all","name":"All"},
+		{"id":"seg_5","name":"Ad hoc"}
+	]}`, nil)
+	ts.mockRq.On("GetCtx", mock.Anything, "/triggers/seg_1", mock.Anything).Return(`{"id":"seg_1","alias":"loyal","name":"Loyal",
+		"is_enabled":true,"triggers":[{"type":"and","conditions":[{"type":"tag","tag":"loyal","operator":"has"}]}]}`, nil)
+	ts.mockRq.On("GetCtx", mock.Anything, "/triggers/seg_2", mock.Anything).Return(`{"id":"seg_2","alias":"same","name":"Same",
+		"is_enabled":true,"triggers":[{"type":"and","conditions":[{"type":"attribute","attribute":"age","operator":"gte","value":18.0}]}]}`, nil)
+}
+
+func (ts *ReconcileTestSuite) definitions() []*SegmentDefinition {
+	return []*SegmentDefinition{
+		{Alias: "loyal", Name: "Loyal customers", Condition: `tag in ("loyal") and event("purchase", within=30d) >= 2`},
+		{Alias: "same", Name: "Same", IsEnabled: boolPtr(true), Condition: `age >= 18`},
+		{Alias: "vip", Name: "VIP", Condition: `tag in ("vip")`},
+	}
+}
+
+func (ts *ReconcileTestSuite) TestPlanSegments_GivenDefinitions_ExpectChangesWithFieldDiffs() {
+	is := assert.New(ts.T())
+
+	plan, err := ts.sdk.PlanSegments(context.Background(), ts.definitions(), &ReconcileOptions{Prune: true, Protect: []string{"system-*"}})
+
+	is.NoError(err)
+	is.Equal(`~ update loyal (seg_1)
+    name: "Loyal" => "Loyal customers"
+    triggers: "tag in (\"loyal\")" => "tag in (\"loyal\") and event(\"purchase\", within=30d) >= 2"
+- delete old (seg_3)
+= no-op same (seg_2)
+= no-op system-all (seg_4): protected from prune
++ create vip
+    name: "" => "VIP"
+    triggers: "" => "tag in (\"vip\")"
+Plan: 1 to create, 1 to update, 1 to delete.
+`, plan.String())
+	is.True(plan.HasChanges())
+}
+
+func (ts *ReconcileTestSuite) TestPlanSegments_GivenIsEnabled_ExpectDiffOnlyWhenSet() {
+	is := assert.New(ts.T())
+	defs := []*SegmentDefinition{
+		{Alias: "loyal", Name: "Loyal", Condition: `tag in ("loyal")`},
+		{Alias: "same", Name: "Same", IsEnabled: boolPtr(false), Condition: `age >= 18`},
+	}
+
+	plan, err := ts.sdk.PlanSegments(context.Background(), defs, nil)
+
+	is.NoError(err)
+	is.Equal(`= no-op loyal (seg_1)
+~ update same (seg_2)
+    is_enabled: "true" => "false"
+Plan: 0 to create, 1 to update, 0 to delete.
+`, plan.String())
+	is.True(plan.Changes[0].Desired.IsEnabled)
+}
+
+func (ts *ReconcileTestSuite) TestPlanSegments_GivenNoPrune_ExpectUndefinedSegmentsLeftAlone() {
+	is := assert.New(ts.T())
+
+	plan, err := ts.sdk.PlanSegments(context.Background(), ts.definitions()[1:2], nil)
+
+	is.NoError(err)
+	is.Len(plan.Changes, 1)
+	is.Equal(ChangeNoop, plan.Changes[0].Action)
+	is.False(plan.HasChanges())
+}
+
+func (ts *ReconcileTestSuite) TestPlanSegments_GivenDuplicateAlias_ExpectError() {
+	is := assert.New(ts.T())
+	defs := append(ts.definitions(), &SegmentDefinition{Alias: "vip", Name: "VIP again", Condition: `tag in ("vip")`, Source: "b.yaml"})
+
+	_, err := ts.sdk.PlanSegments(context.Background(), defs, nil)
+
+	is.EqualError(err, `pam segment definition vip (b.yaml): alias is defined more than once`)
+	ts.mockRq.AssertNotCalled(ts.T(), "GetCtx", mock.Anything, "/triggers", mock.Anything)
+}
+
+func (ts *ReconcileTestSuite) TestReconcileSegments_GivenPlan_ExpectCreateUpdateThenDelete() {
+	is := assert.New(ts.T())
+	var order []string
+	record := func(args mock.Arguments) { order = append(order, args.String(1)) }
+	ts.mockRq.On("PostJSONCtx", mock.Anything, "/triggers", mock.MatchedBy(func(s *Segment) bool {
+		return s.Alias == "vip" && len(s.Triggers) == 1
+	})).Run(record).Return(`{"id":"seg_9","alias":"vip"}`, nil)
+	ts.mockRq.On("PutJSONCtx", mock.Anything, "/triggers/seg_1", mock.MatchedBy(func(s *Segment) bool {
+		return s.Name == "Loyal customers" && s.IsEnabled
+	})).Run(record).Return(`{"id":"seg_1"}`, nil)
+	ts.mockRq.On("DeleteCtx", mock.Anything, "/triggers/seg_3", mock.Anything).Run(record).Return(`{"status":"success"}`, nil)
+
+	plan, err := ts.sdk.ReconcileSegments(context.Background(), ts.definitions(), &ReconcileOptions{Prune: true, Protect: []string{"system-*"}})
+
+	is.NoError(err)
+	is.Equal([]string{"/triggers", "/triggers/seg_1", "/triggers/seg_3"}, order)
+	for _, c := range plan.Changes {
+		is.Equal(c.Action != ChangeNoop, c.Applied, c.Alias)
+		if c.Alias == "vip" {
+			is.Equal("seg_9", c.SegmentID)
+		}
+	}
+	ts.mockRq.AssertNotCalled(ts.T(), "PutJSONCtx", mock.Anything, "/triggers/seg_2", mock.Anything)
+	ts.mockRq.AssertNotCalled(ts.T(), "DeleteCtx", mock.Anything, "/triggers/seg_4", mock.Anything)
+}
+
+func (ts *ReconcileTestSuite) TestReconcileSegments_GivenDryRun_ExpectPlanWithoutChanges() {
+	is := assert.New(ts.T())
+
+	plan, err := ts.sdk.ReconcileSegments(context.Background(), ts.definitions(), &ReconcileOptions{Prune: true, DryRun: true})
+
+	is.NoError(err)
+	is.Equal(2, plan.Count(ChangeDelete))
+	ts.mockRq.AssertNotCalled(ts.T(), "PostJSONCtx", mock.Anything, mock.Anything, mock.Anything)
+	ts.mockRq.AssertNotCalled(ts.T(), "PutJSONCtx", mock.Anything, mock.Anything, mock.Anything)
+	ts.mockRq.AssertNotCalled(ts.T(), "DeleteCtx", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (ts *ReconcileTestSuite) TestApplySegmentPlan_GivenTooManyDeletes_ExpectErrorBeforeAnyChange() {
+	is := assert.New(ts.T())
+	opts := &ReconcileOptions{Prune: true, MaxDeletes: 1}
+	plan, err := ts.sdk.PlanSegments(context.Background(), ts.definitions(), opts)
+	is.NoError(err)
+
+	err = ts.sdk.ApplySegmentPlan(context.Background(), plan, opts)
+
+	is.EqualError(err, `pam segment plan delete 2 segments, more than limit 1`)
+	ts.mockRq.AssertNotCalled(ts.T(), "PostJSONCtx", mock.Anything, mock.Anything, mock.Anything)
+	ts.mockRq.AssertNotCalled(ts.T(), "DeleteCtx", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (ts *ReconcileTestSuite) TestReconcileSegments_GivenDryRunWithTooManyDeletes_ExpectPlanAndError() {
+	is := assert.New(ts.T())
+
+	plan, err := ts.sdk.ReconcileSegments(context.Background(), ts.definitions(), &ReconcileOptions{Prune: true, MaxDeletes: 1, DryRun: true})
+
+	is.EqualError(err, `pam segment plan delete 2 segments, more than limit 1`)
+	is.Equal(2, plan.Count(ChangeDelete))
+}
+
+func (ts *ReconcileTestSuite) TestApplySegmentPlan_GivenDefaultOptions_ExpectDeletesCapped() {
+	is := assert.New(ts.T())
+	plan := &SegmentPlan{}
+	for i := 0; i <= DefaultMaxDeletes; i++ {
+		plan.Changes = append(plan.Changes, &SegmentChange{Action: ChangeDelete, Alias: "old", SegmentID: "seg_3"})
+	}
+
+	err := ts.sdk.ApplySegmentPlan(context.Background(), plan, nil)
+
+	is.EqualError(err, `pam segment plan delete 6 segments, more than limit 5`)
+	ts.mockRq.AssertNotCalled(ts.T(), "DeleteCtx", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (ts *ReconcileTestSuite) TestPlanSegments_GivenPruneWithoutDefinitions_ExpectError() {
+	is := assert.New(ts.T())
+
+	_, err := ts.sdk.PlanSegments(context.Background(), nil, &ReconcileOptions{Prune: true})
+
+	is.EqualError(err, `pam segment prune without definitions would delete every segment`)
+	ts.mockRq.AssertNotCalled(ts.T(), "GetCtx", mock.Anything, "/triggers", mock.Anything)
+}
+
+func (ts *ReconcileTestSuite) TestPlanSegments_GivenMalformedProtectPattern_ExpectErrBadPattern() {
+	is := assert.New(ts.T())
+
+	_, err := ts.sdk.PlanSegments(context.Background(), ts.definitions(), &ReconcileOptions{Prune: true, Protect: []string{"system-[a"}})
+
+	is.True(errors.Is(err, path.ErrBadPattern))
+	ts.mockRq.AssertNotCalled(ts.T(), "GetCtx", mock.Anything, "/triggers", mock.Anything)
+}
+
+func (ts *ReconcileTestSuite) TestPlanSegments_GivenSegmentFromPAMUnchanged_ExpectNoop() {
+	is := assert.New(ts.T())
+	mockRq := NewMockRequester()
+	sdk := NewSdkR(nil, &RequestLogger{rq: mockRq, logger: NewMockLogger()})
+	fixture, err := os.ReadFile("testdata/segment_by_id.json")
+	is.NoError(err)
+	mockRq.On("GetCtx", mock.Anything, "/triggers", mock.Anything).Return(`{"page":1,"limit":50,"data":[
+		{"id":"5f1a7c2e9b1d4a0012ab34cd","alias":"bkk-shoppers","name":"Bangkok shoppers"},
+		{"id":"seg_6","alias":"buyers","name":"Buyers"}
+	]}`, nil)
+	mockRq.On("GetCtx", mock.Anything, "/triggers/5f1a7c2e9b1d4a0012ab34cd", mock.Anything).Return(string(fixture), nil)
+	// PAM return numeric window and condition id which the SDK does not write
+	mockRq.On("GetCtx", mock.Anything, "/triggers/seg_6", mock.Anything).Return(`{"id":"seg_6","alias":"buyers","name":"Buyers",
+		"triggers":[{"type":"and","conditions":[
+			{"id":"c_1","type":"event","event":"purchase","operator":"gte","count":2,"within_amount":30,"within_unit":"day"}
+		]}]}`, nil)
+
+	exported := &SegmentDefinition{}
+	is.NoError(json.Unmarshal(fixture, exported))
+	defs := []*SegmentDefinition{exported, {Alias: "buyers", Name: "Buyers", Condition: `event("purchase", within=30d) >= 2`}}
+	plan, err := sdk.PlanSegments(context.Background(), defs, nil)
+
+	if is.NoError(err) {
+		is.False(plan.HasChanges(), plan.String())
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
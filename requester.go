@@ -1,43 +1,72 @@
 package pam4sdk
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
-
-	"github.com/3dsinteractive/gorequest"
 )
 
 // IRequester interface for http request
 type IRequester interface {
 	Get(path string, params map[string]string) (string, error)
+	GetCtx(ctx context.Context, path string, params map[string]string) (string, error)
 	GetR(path string, params map[string]string) (*http.Response, string, error)
+	GetRCtx(ctx context.Context, path string, params map[string]string) (*http.Response, string, error)
 	GetRH(path string, params map[string]string, headers map[string]string) (*http.Response, string, error)
+	GetRHCtx(ctx context.Context, path string, params map[string]string, headers map[string]string) (*http.Response, string, error)
 	GetRHC(path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error)
+	GetRHCCtx(ctx context.Context, path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error)
 	Post(path string, params map[string]string) (string, error)
+	PostCtx(ctx context.Context, path string, params map[string]string) (string, error)
 	PostR(path string, params map[string]string) (*http.Response, string, error)
+	PostRCtx(ctx context.Context, path string, params map[string]string) (*http.Response, string, error)
 	PostRH(path string, params map[string]string, headers map[string]string) (*http.Response, string, error)
+	PostRHCtx(ctx context.Context, path string, params map[string]string, headers map[string]string) (*http.Response, string, error)
 	PostRHC(path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error)
+	PostRHCCtx(ctx context.Context, path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error)
 	PostRaw(path string, data interface{}, headers map[string]string) (*http.Response, string, error)
+	PostRawCtx(ctx context.Context, path string, data interface{}, headers map[string]string) (*http.Response, string, error)
 	PostJSON(path string, body interface{}) (string, error)
+	PostJSONCtx(ctx context.Context, path string, body interface{}) (string, error)
 	PostJSONR(path string, body interface{}) (*http.Response, string, error)
+	PostJSONRCtx(ctx context.Context, path string, body interface{}) (*http.Response, string, error)
 	PostJSONRH(path string, body interface{}, headers map[string]string) (*http.Response, string, error)
+	PostJSONRHCtx(ctx context.Context, path string, body interface{}, headers map[string]string) (*http.Response, string, error)
 	PostJSONRHC(path string, body interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error)
+	PostJSONRHCCtx(ctx context.Context, path string, body interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error)
 	PostFile(path string, filePath string, postParam string, extraData string) (string, error)
+	PostFileCtx(ctx context.Context, path string, filePath string, postParam string, extraData string) (string, error)
 	PutJSON(path string, body interface{}) (string, error)
+	PutJSONCtx(ctx context.Context, path string, body interface{}) (string, error)
 	PutJSONRH(path string, body interface{}, headers map[string]string) (*http.Response, string, error)
+	PutJSONRHCtx(ctx context.Context, path string, body interface{}, headers map[string]string) (*http.Response, string, error)
 	PutJSONRHC(path string, body interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error)
+	PutJSONRHCCtx(ctx context.Context, path string, body interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error)
 	Delete(path string, params map[string]string) (string, error)
+	DeleteCtx(ctx context.Context, path string, params map[string]string) (string, error)
 	DeleteR(path string, params map[string]string) (*http.Response, string, error)
+	DeleteRCtx(ctx context.Context, path string, params map[string]string) (*http.Response, string, error)
 	DeleteRH(path string, params map[string]string, headers map[string]string) (*http.Response, string, error)
+	DeleteRHCtx(ctx context.Context, path string, params map[string]string, headers map[string]string) (*http.Response, string, error)
 	DeleteRHC(path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error)
+	DeleteRHCCtx(ctx context.Context, path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error)
 	DeleteJSON(path string, body interface{}) (string, error)
+	DeleteJSONCtx(ctx context.Context, path string, body interface{}) (string, error)
 	DeleteJSONR(path string, body interface{}) (*http.Response, string, error)
+	DeleteJSONRCtx(ctx context.Context, path string, body interface{}) (*http.Response, string, error)
 	DeleteJSONRH(path string, body interface{}, headers map[string]string) (*http.Response, string, error)
+	DeleteJSONRHCtx(ctx context.Context, path string, body interface{}, headers map[string]string) (*http.Response, string, error)
 	DeleteJSONRHC(path string, body interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error)
+	DeleteJSONRHCCtx(ctx context.Context, path string, body interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error)
 }
 
 // IRequesterConfig config for requester
@@ -108,236 +137,458 @@ func (cfg *CustomRequesterConfig) Timeout() time.Duration {
 	return cfg.timeout
 }
 
-// Requester struct implement IRequester
+// Requester struct implement IRequester on top of net/http, it is safe for concurrent use.
+// Its fields are never changed after NewRequester, every call build its own http.Request
 type Requester struct {
-	config IRequesterConfig
-	logger ILogger
-	req    *gorequest.SuperAgent
+	config      IRequesterConfig
+	logger      ILogger
+	client      *http.Client
+	retry       *RetryPolicy
+	limiter     *RateLimiter
+	breaker     *CircuitBreaker
+	connector   string
+	metrics     MetricsCollector
+	tracer      Tracer
+	middlewares []Middleware
+	handler     Handler
 }
 
-// NewRequester return new Requester
-func NewRequester(config IRequesterConfig, logger ILogger) *Requester {
-	return &Requester{
-		config: config,
-		logger: logger,
+// RequesterOption configure optional behaviour of Requester
+type RequesterOption func(*Requester)
+
+// RequesterHTTPClient send requests through given http client instead of the default one
+func RequesterHTTPClient(client *http.Client) RequesterOption {
+	return func(rqt *Requester) {
+		rqt.client = client
 	}
 }
 
-func (rqt *Requester) setupCredential(r *gorequest.SuperAgent) *gorequest.SuperAgent {
-	appIDKey, appID := rqt.config.AppIDHeaderKey(), rqt.config.AppID()
-	if len(appIDKey) > 0 && len(appID) > 0 {
-		r.Set(appIDKey, appID)
+// RequesterTransport send requests through given round tripper, useful for proxies and test doubles
+func RequesterTransport(transport http.RoundTripper) RequesterOption {
+	return func(rqt *Requester) {
+		rqt.client = &http.Client{Transport: transport}
 	}
-	secretKey, secret := rqt.config.SecretHeaderKey(), rqt.config.Secret()
-	if len(secretKey) > 0 && len(secret) > 0 {
-		r.Set(secretKey, secret)
-	}
-	return r
 }
 
-func (rqt *Requester) cloneR() *gorequest.SuperAgent {
-	r := rqt.req
-	if r == nil {
-		r = gorequest.New()
-		rqt.req = r
+// RequesterRetryPolicy retry failed request according to policy
+func RequesterRetryPolicy(policy *RetryPolicy) RequesterOption {
+	return func(rqt *Requester) {
+		rqt.retry = policy
 	}
-	// Timeout is relative to time.Now so we need to set every time
-	r.Timeout(rqt.config.Timeout())
-	return r.Clone()
 }
 
-// Get make a GET request
-func (rqt *Requester) Get(path string, params map[string]string) (string, error) {
-	_, body, err := rqt.GetR(path, params)
-	return body, err
+// RequesterRateLimiter make every request attempt wait for limiter
+func RequesterRateLimiter(limiter *RateLimiter) RequesterOption {
+	return func(rqt *Requester) {
+		rqt.limiter = limiter
+	}
 }
 
-func (rqt *Requester) GetR(path string, params map[string]string) (*http.Response, string, error) {
-	res, body, err := rqt.GetRH(path, params, nil)
-	return res, body, err
+// RequesterCircuitBreaker fail fast with ErrCircuitOpen while breaker is open
+func RequesterCircuitBreaker(breaker *CircuitBreaker) RequesterOption {
+	return func(rqt *Requester) {
+		rqt.breaker = breaker
+	}
 }
 
-func (rqt *Requester) GetRH(path string, params map[string]string, headers map[string]string) (*http.Response, string, error) {
-	return rqt.GetRHC(path, params, headers, nil)
+// RequesterConnector set connector name reported to middlewares and metrics
+func RequesterConnector(name string) RequesterOption {
+	return func(rqt *Requester) {
+		rqt.connector = name
+	}
 }
 
-func (rqt *Requester) GetRHC(path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
-	r := rqt.cloneR()
-
-	url := fmt.Sprint(rqt.config.Endpoint(), path)
-	rqt.logger.Debug("[RQT GET]: " + url)
-	r = r.Get(url)
-	rqt.setupCredential(r)
-	if params != nil {
-		for key, value := range params {
-			r = r.Param(key, value)
-		}
+// RequesterMetrics report every request to collector
+func RequesterMetrics(collector MetricsCollector) RequesterOption {
+	return func(rqt *Requester) {
+		rqt.metrics = collector
 	}
+}
 
-	if headers != nil {
-		for key, value := range headers {
-			r.Header.Add(key, value)
-		}
+// RequesterTracer start span for every attempt and propagate it to PAM in traceparent header
+func RequesterTracer(tracer Tracer) RequesterOption {
+	return func(rqt *Requester) {
+		rqt.tracer = tracer
 	}
+}
 
-	if len(cookies) > 0 {
-		for _, c := range cookies {
-			r.AddCookie(c)
-		}
+// RequesterMiddleware wrap every request with middlewares, the first one is the outermost.
+// They run outside of retry, circuit breaker and rate limiter so they see each call once
+func RequesterMiddleware(mws ...Middleware) RequesterOption {
+	return func(rqt *Requester) {
+		rqt.middlewares = append(rqt.middlewares, mws...)
 	}
+}
 
-	res, body, errs := r.End()
-	if len(errs) > 0 {
-		return res, "", NewErrorE(rqt.logger, errs[0])
+// NewRequester return new Requester
+func NewRequester(config IRequesterConfig, logger ILogger, opts ...RequesterOption) *Requester {
+	rqt := &Requester{
+		config: config,
+		logger: logger,
 	}
-	rqt.logger.Debug(fmt.Sprintf("[RQT GET-RESP]: %s %s", url, rqt.truncateLogBody(body)))
-	if res.StatusCode >= 400 {
-		return res, body, NewErrM(res.Status)
+	for _, opt := range opts {
+		opt(rqt)
 	}
-
-	return res, body, nil
-}
-
-// Post make a POST request
-func (rqt *Requester) Post(path string, params map[string]string) (string, error) {
-	_, body, err := rqt.PostR(path, params)
-	return body, err
+	if rqt.client == nil {
+		// Zero client use http.DefaultTransport, so connections are pooled across requesters
+		rqt.client = &http.Client{}
+	}
+	mws := append([]Middleware{}, rqt.middlewares...)
+	mws = append(mws,
+		MetricsMiddleware(rqt.metrics),
+		RetryMiddleware(rqt.retry, rqt.logger),
+		TracingMiddleware(rqt.tracer),
+		CircuitBreakerMiddleware(rqt.breaker),
+		RateLimitMiddleware(rqt.limiter))
+	rqt.handler = Chain(rqt.send, mws...)
+	return rqt
 }
 
-func (rqt *Requester) PostR(path string, params map[string]string) (*http.Response, string, error) {
-	return rqt.PostRH(path, params, nil)
+// request describe a call made by IRequester verbs, it is turned into Request for middlewares
+type request struct {
+	method      string
+	path        string
+	params      map[string]string
+	headers     map[string]string
+	cookies     []*http.Cookie
+	contentType string
+	body        []byte
 }
 
-func (rqt *Requester) PostRH(path string, params map[string]string, headers map[string]string) (*http.Response, string, error) {
-	return rqt.PostRHC(path, params, headers, nil)
+func (rqt *Requester) url(r *request) string {
+	u := fmt.Sprint(rqt.config.Endpoint(), r.path)
+	if len(r.params) == 0 {
+		return u
+	}
+	query := url.Values{}
+	for key, value := range r.params {
+		query.Set(key, value)
+	}
+	if strings.Contains(u, "?") {
+		return u + "&" + query.Encode()
+	}
+	return u + "?" + query.Encode()
 }
 
-func (rqt *Requester) PostRHC(path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
-	r := rqt.cloneR()
-
-	u := fmt.Sprint(rqt.config.Endpoint(), path)
-	rqt.logger.Debug("[RQT POST]: " + u)
-	r = r.Post(u)
-	rqt.setupCredential(r)
-	if params != nil {
-		postData := url.Values{}
-		for key, value := range params {
-			postData.Add(key, value)
-		}
-		r = r.Send(postData.Encode())
+func (rqt *Requester) header(r *request) http.Header {
+	header := http.Header{}
+	appIDKey, appID := rqt.config.AppIDHeaderKey(), rqt.config.AppID()
+	if len(appIDKey) > 0 && len(appID) > 0 {
+		header.Set(appIDKey, appID)
 	}
-
-	if headers != nil {
-		for key, value := range headers {
-			r.Header.Add(key, value)
-		}
+	secretKey, secret := rqt.config.SecretHeaderKey(), rqt.config.Secret()
+	if len(secretKey) > 0 && len(secret) > 0 {
+		header.Set(secretKey, secret)
+	}
+	if len(r.contentType) > 0 {
+		header.Set("Content-Type", r.contentType)
+	}
+	for key, value := range r.headers {
+		header.Set(key, value)
 	}
+	return header
+}
 
-	if len(cookies) > 0 {
-		for _, c := range cookies {
-			r.AddCookie(c)
-		}
+// do send r through middlewares and convert response with status >= 400 to APIError
+func (rqt *Requester) do(ctx context.Context, r *request) (*http.Response, string, error) {
+	req := &Request{
+		Method:    r.method,
+		URL:       rqt.url(r),
+		Path:      r.path,
+		Route:     r.path,
+		Connector: rqt.connector,
+		Header:    rqt.header(r),
+		Cookies:   r.cookies,
+		Body:      r.body,
 	}
+	if route, ok := routeFromContext(ctx); ok {
+		req.Route = route
+	}
+	rqt.logger.Debug(fmt.Sprintf("[RQT %s]: %s", req.Method, req.URL))
 
-	res, body, errs := r.End()
-	if len(errs) > 0 {
-		return res, "", NewErrorE(rqt.logger, errs[0])
+	res, body, err := rqt.handler(ctx, req)
+	if err != nil {
+		return res, body, NewErrorE(rqt.logger, err)
 	}
-	rqt.logger.Debug(fmt.Sprintf("[RQT POST-RESP]: %s %s", u, rqt.truncateLogBody(body)))
+	rqt.logger.Debug(fmt.Sprintf("[RQT %s-RESP]: %s %s", req.Method, req.URL, rqt.truncateLogBody(body)))
 	if res.StatusCode >= 400 {
-		return res, body, NewErrM(res.Status)
+		return res, body, NewAPIError(req.Method, req.Path, res, body)
 	}
 	return res, body, nil
 }
 
-func (rqt *Requester) PostRaw(path string, data interface{}, headers map[string]string) (*http.Response, string, error) {
-	r := rqt.cloneR()
+// send make a single attempt of req, the request is aborted when ctx is cancelled or its deadline exceeded.
+// Zero timeout of config mean no timeout like http.Client
+func (rqt *Requester) send(ctx context.Context, req *Request) (*http.Response, string, error) {
+	if timeout := rqt.config.Timeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
-	u := fmt.Sprint(rqt.config.Endpoint(), path)
-	rqt.logger.Debug("[RQT POST]: " + u)
-	r = r.Post(u)
-	rqt.setupCredential(r)
-	r = r.Send(data)
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, "", err
+	}
+	httpReq.Header = req.Header.Clone()
+	for _, c := range req.Cookies {
+		httpReq.AddCookie(c)
+	}
 
-	if headers != nil {
-		for key, value := range headers {
-			r.Header.Add(key, value)
+	res, err := rqt.client.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, "", ctx.Err()
 		}
+		return nil, "", err
 	}
+	defer res.Body.Close()
 
-	res, body, errs := r.End()
-	if len(errs) > 0 {
-		return res, "", NewErrorE(rqt.logger, errs[0])
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return res, "", err
 	}
-	rqt.logger.Debug(fmt.Sprintf("[RQT POST-RESP]: %s %s", u, rqt.truncateLogBody(body)))
-	if res.StatusCode >= 400 {
-		return res, body, NewErrM(res.Status)
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return res, string(body), nil
+}
+
+// encodeForm encode params as application/x-www-form-urlencoded body
+func encodeForm(params map[string]string) []byte {
+	values := url.Values{}
+	for key, value := range params {
+		values.Add(key, value)
 	}
-	return res, body, nil
+	return []byte(values.Encode())
 }
 
-func (rqt *Requester) PostJSONRHC(path string, jsonBody interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
-	r := rqt.cloneR()
+// encodeJSON encode v as JSON body, string and []byte are assumed to be JSON already
+func encodeJSON(v interface{}) ([]byte, error) {
+	switch data := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(data), nil
+	case []byte:
+		return data, nil
+	}
+	return json.Marshal(v)
+}
 
-	url := fmt.Sprint(rqt.config.Endpoint(), path)
-	rqt.logger.Debug("[RQT POST]: " + url)
-	r = r.Post(url)
-	rqt.setupCredential(r)
-	if jsonBody != nil {
-		r = r.Send(jsonBody)
+// encodeRaw encode data sent by PostRaw and return its content type,
+// string and []byte are sent as they are and detected as either JSON or form
+func encodeRaw(data interface{}) ([]byte, string, error) {
+	var body []byte
+	switch v := data.(type) {
+	case nil:
+		return nil, "", nil
+	case string:
+		body = []byte(v)
+	case []byte:
+		body = v
+	default:
+		b, err := json.Marshal(v)
+		return b, "application/json", err
+	}
+	if json.Valid(body) {
+		return body, "application/json", nil
 	}
+	return body, "application/x-www-form-urlencoded", nil
+}
 
-	if headers != nil {
-		for key, value := range headers {
-			r.Header.Add(key, value)
-		}
+// encodeMultipart write fields given as query string and a file into multipart body
+func encodeMultipart(fields string, fieldName string, fileName string, content []byte) ([]byte, string, error) {
+	values, err := url.ParseQuery(fields)
+	if err != nil {
+		return nil, "", err
 	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-	if len(cookies) > 0 {
-		for _, c := range cookies {
-			r.AddCookie(c)
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	for _, key := range keys {
+		for _, value := range values[key] {
+			if err := w.WriteField(key, value); err != nil {
+				return nil, "", err
+			}
 		}
 	}
+	part, err := w.CreateFormFile(fieldName, fileName)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(content); err != nil {
+		return nil, "", err
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
 
-	res, body, errs := r.End()
-	if len(errs) > 0 {
-		for _, err := range errs {
-			rqt.logger.Debug(fmt.Sprintf("[RQT POST-ERR]: %s", err.Error()))
-		}
-		if res != nil {
-			return res, body, NewErrM(res.Status)
-		} else {
-			return res, body, NewErrM(errs[0].Error())
-		}
+// Get make a GET request
+func (rqt *Requester) Get(path string, params map[string]string) (string, error) {
+	return rqt.GetCtx(context.Background(), path, params)
+}
+
+// GetCtx make a GET request with context
+func (rqt *Requester) GetCtx(ctx context.Context, path string, params map[string]string) (string, error) {
+	_, body, err := rqt.GetRCtx(ctx, path, params)
+	return body, err
+}
+
+func (rqt *Requester) GetR(path string, params map[string]string) (*http.Response, string, error) {
+	return rqt.GetRCtx(context.Background(), path, params)
+}
+
+func (rqt *Requester) GetRCtx(ctx context.Context, path string, params map[string]string) (*http.Response, string, error) {
+	res, body, err := rqt.GetRHCtx(ctx, path, params, nil)
+	return res, body, err
+}
+
+func (rqt *Requester) GetRH(path string, params map[string]string, headers map[string]string) (*http.Response, string, error) {
+	return rqt.GetRHCtx(context.Background(), path, params, headers)
+}
+
+func (rqt *Requester) GetRHCtx(ctx context.Context, path string, params map[string]string, headers map[string]string) (*http.Response, string, error) {
+	return rqt.GetRHCCtx(ctx, path, params, headers, nil)
+}
+
+func (rqt *Requester) GetRHC(path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	return rqt.GetRHCCtx(context.Background(), path, params, headers, cookies)
+}
+
+func (rqt *Requester) GetRHCCtx(ctx context.Context, path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	return rqt.do(ctx, &request{
+		method:  http.MethodGet,
+		path:    path,
+		params:  params,
+		headers: headers,
+		cookies: cookies,
+	})
+}
+
+// Post make a POST request
+func (rqt *Requester) Post(path string, params map[string]string) (string, error) {
+	return rqt.PostCtx(context.Background(), path, params)
+}
+
+// PostCtx make a POST request with context
+func (rqt *Requester) PostCtx(ctx context.Context, path string, params map[string]string) (string, error) {
+	_, body, err := rqt.PostRCtx(ctx, path, params)
+	return body, err
+}
+
+func (rqt *Requester) PostR(path string, params map[string]string) (*http.Response, string, error) {
+	return rqt.PostRCtx(context.Background(), path, params)
+}
+
+func (rqt *Requester) PostRCtx(ctx context.Context, path string, params map[string]string) (*http.Response, string, error) {
+	return rqt.PostRHCtx(ctx, path, params, nil)
+}
+
+func (rqt *Requester) PostRH(path string, params map[string]string, headers map[string]string) (*http.Response, string, error) {
+	return rqt.PostRHCtx(context.Background(), path, params, headers)
+}
+
+func (rqt *Requester) PostRHCtx(ctx context.Context, path string, params map[string]string, headers map[string]string) (*http.Response, string, error) {
+	return rqt.PostRHCCtx(ctx, path, params, headers, nil)
+}
+
+func (rqt *Requester) PostRHC(path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	return rqt.PostRHCCtx(context.Background(), path, params, headers, cookies)
+}
+
+func (rqt *Requester) PostRHCCtx(ctx context.Context, path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	r := &request{
+		method:  http.MethodPost,
+		path:    path,
+		headers: headers,
+		cookies: cookies,
+	}
+	if params != nil {
+		r.contentType = "application/x-www-form-urlencoded"
+		r.body = encodeForm(params)
 	}
+	return rqt.do(ctx, r)
+}
 
-	rqt.logger.Debug(fmt.Sprintf("[RQT POST-RESP]: %s %s", url, rqt.truncateLogBody(body)))
+func (rqt *Requester) PostRaw(path string, data interface{}, headers map[string]string) (*http.Response, string, error) {
+	return rqt.PostRawCtx(context.Background(), path, data, headers)
+}
 
-	if res.StatusCode >= 400 {
-		return res, body, NewErrM(res.Status)
+func (rqt *Requester) PostRawCtx(ctx context.Context, path string, data interface{}, headers map[string]string) (*http.Response, string, error) {
+	body, contentType, err := encodeRaw(data)
+	if err != nil {
+		return nil, "", NewErrorE(rqt.logger, err)
 	}
-	return res, body, nil
+	return rqt.do(ctx, &request{
+		method:      http.MethodPost,
+		path:        path,
+		headers:     headers,
+		contentType: contentType,
+		body:        body,
+	})
+}
+
+func (rqt *Requester) PostJSONRHC(path string, jsonBody interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	return rqt.PostJSONRHCCtx(context.Background(), path, jsonBody, headers, cookies)
+}
+
+func (rqt *Requester) PostJSONRHCCtx(ctx context.Context, path string, jsonBody interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	body, err := encodeJSON(jsonBody)
+	if err != nil {
+		return nil, "", NewErrorE(rqt.logger, err)
+	}
+	return rqt.do(ctx, &request{
+		method:      http.MethodPost,
+		path:        path,
+		headers:     headers,
+		cookies:     cookies,
+		contentType: "application/json",
+		body:        body,
+	})
 }
 
 // PostJSONRH make a POST request with JSON body
 func (rqt *Requester) PostJSONRH(path string, jsonBody interface{}, headers map[string]string) (*http.Response, string, error) {
-	return rqt.PostJSONRHC(path, jsonBody, headers, nil)
+	return rqt.PostJSONRHCtx(context.Background(), path, jsonBody, headers)
+}
+
+// PostJSONRHCtx make a POST request with JSON body with context
+func (rqt *Requester) PostJSONRHCtx(ctx context.Context, path string, jsonBody interface{}, headers map[string]string) (*http.Response, string, error) {
+	return rqt.PostJSONRHCCtx(ctx, path, jsonBody, headers, nil)
 }
 
 func (rqt *Requester) PostJSONR(path string, jsonBody interface{}) (*http.Response, string, error) {
-	return rqt.PostJSONRH(path, jsonBody, nil)
+	return rqt.PostJSONRCtx(context.Background(), path, jsonBody)
+}
+
+func (rqt *Requester) PostJSONRCtx(ctx context.Context, path string, jsonBody interface{}) (*http.Response, string, error) {
+	return rqt.PostJSONRHCtx(ctx, path, jsonBody, nil)
 }
 
 // PostJSON make a POST request with JSON body
 func (rqt *Requester) PostJSON(path string, jsonBody interface{}) (string, error) {
-	_, body, err := rqt.PostJSONRH(path, jsonBody, nil)
+	return rqt.PostJSONCtx(context.Background(), path, jsonBody)
+}
+
+// PostJSONCtx make a POST request with JSON body with context
+func (rqt *Requester) PostJSONCtx(ctx context.Context, path string, jsonBody interface{}) (string, error) {
+	_, body, err := rqt.PostJSONRHCtx(ctx, path, jsonBody, nil)
 	return body, err
 }
 
 // PostFile send file using HTTP POST
 func (rqt *Requester) PostFile(path string, filePath string, postParam string, extraData string) (string, error) {
-	r := rqt.cloneR()
+	return rqt.PostFileCtx(context.Background(), path, filePath, postParam, extraData)
+}
 
+// PostFileCtx send file using HTTP POST with context
+func (rqt *Requester) PostFileCtx(ctx context.Context, path string, filePath string, postParam string, extraData string) (string, error) {
 	f, err := filepath.Abs(filePath)
 	if err != nil {
 		return "", NewErrorE(rqt.logger, err)
@@ -346,197 +597,154 @@ func (rqt *Requester) PostFile(path string, filePath string, postParam string, e
 	if err != nil {
 		return "", NewErrorE(rqt.logger, err)
 	}
+	rqt.logger.Debug("[RQT POSTFILE]: " + path + " : FILEPATH:" + filePath)
 
-	url := fmt.Sprint(rqt.config.Endpoint(), path)
-	rqt.logger.Debug("[RQT POSTFILE]: " + url + " : FILEPATH:" + filePath)
-
-	r = r.Post(url)
-	rqt.setupCredential(r)
-	r = r.Type("multipart")
-
-	if extraData != "" {
-		r.Send(extraData)
-	}
-
-	r.SendFile(bytesOfFile, filepath.Base(filePath), postParam)
-	res, body, errs := r.End()
-	if len(errs) > 0 {
-		for _, e := range errs {
-			err = NewErrorE(rqt.logger, e)
-		}
-		return "", err
-	}
-	rqt.logger.Debug(fmt.Sprintf("[RQT POST-RESP]: %s %s", url, rqt.truncateLogBody(body)))
-	if res.StatusCode >= 400 {
-		return body, NewErrM(res.Status)
+	body, contentType, err := encodeMultipart(extraData, postParam, filepath.Base(filePath), bytesOfFile)
+	if err != nil {
+		return "", NewErrorE(rqt.logger, err)
 	}
-	return body, nil
+	_, resBody, err := rqt.do(ctx, &request{
+		method:      http.MethodPost,
+		path:        path,
+		contentType: contentType,
+		body:        body,
+	})
+	return resBody, err
 }
 
 // PutJSON make a PUT request with JSON body
 func (rqt *Requester) PutJSON(path string, jsonBody interface{}) (string, error) {
-	_, body, err := rqt.PutJSONRH(path, jsonBody, nil)
+	return rqt.PutJSONCtx(context.Background(), path, jsonBody)
+}
+
+// PutJSONCtx make a PUT request with JSON body with context
+func (rqt *Requester) PutJSONCtx(ctx context.Context, path string, jsonBody interface{}) (string, error) {
+	_, body, err := rqt.PutJSONRHCtx(ctx, path, jsonBody, nil)
 	return body, err
 }
 
 // PutJSONRH make a PUT request with JSON body
 func (rqt *Requester) PutJSONRH(path string, jsonBody interface{}, headers map[string]string) (*http.Response, string, error) {
-	return rqt.PutJSONRHC(path, jsonBody, headers, nil)
+	return rqt.PutJSONRHCtx(context.Background(), path, jsonBody, headers)
 }
 
-func (rqt *Requester) PutJSONRHC(path string, jsonBody interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
-	r := rqt.cloneR()
-
-	url := fmt.Sprint(rqt.config.Endpoint(), path)
-	rqt.logger.Debug("[RQT PUT]: " + url)
-	r = r.Put(url)
-	rqt.setupCredential(r)
-	if jsonBody != nil {
-		r = r.Send(jsonBody)
-	}
-
-	if headers != nil {
-		for key, value := range headers {
-			r.Header.Add(key, value)
-		}
-	}
-
-	if len(cookies) > 0 {
-		for _, c := range cookies {
-			r.AddCookie(c)
-		}
-	}
-
-	res, body, errs := r.End()
-	if len(errs) > 0 {
-		for _, err := range errs {
-			rqt.logger.Debug(fmt.Sprintf("[RQT PUT-ERR]: %s", err.Error()))
-		}
-		if res != nil {
-			return res, body, NewErrM(res.Status)
-		} else {
-			return res, body, NewErrM(errs[0].Error())
-		}
-	}
+// PutJSONRHCtx make a PUT request with JSON body with context
+func (rqt *Requester) PutJSONRHCtx(ctx context.Context, path string, jsonBody interface{}, headers map[string]string) (*http.Response, string, error) {
+	return rqt.PutJSONRHCCtx(ctx, path, jsonBody, headers, nil)
+}
 
-	rqt.logger.Debug(fmt.Sprintf("[RQT PUT-RESP]: %s %s", url, rqt.truncateLogBody(body)))
+func (rqt *Requester) PutJSONRHC(path string, jsonBody interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	return rqt.PutJSONRHCCtx(context.Background(), path, jsonBody, headers, cookies)
+}
 
-	if res.StatusCode >= 400 {
-		return res, body, NewErrM(res.Status)
+func (rqt *Requester) PutJSONRHCCtx(ctx context.Context, path string, jsonBody interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	body, err := encodeJSON(jsonBody)
+	if err != nil {
+		return nil, "", NewErrorE(rqt.logger, err)
 	}
-	return res, body, nil
+	return rqt.do(ctx, &request{
+		method:      http.MethodPut,
+		path:        path,
+		headers:     headers,
+		cookies:     cookies,
+		contentType: "application/json",
+		body:        body,
+	})
 }
 
 // Delete make a DELETE request
 func (rqt *Requester) Delete(path string, params map[string]string) (string, error) {
-	_, body, err := rqt.DeleteR(path, params)
+	return rqt.DeleteCtx(context.Background(), path, params)
+}
+
+// DeleteCtx make a DELETE request with context
+func (rqt *Requester) DeleteCtx(ctx context.Context, path string, params map[string]string) (string, error) {
+	_, body, err := rqt.DeleteRCtx(ctx, path, params)
 	return body, err
 }
 
 // DeleteR make a DELETE request and response http.Response
 func (rqt *Requester) DeleteR(path string, params map[string]string) (*http.Response, string, error) {
-	res, body, err := rqt.DeleteRH(path, params, nil)
+	return rqt.DeleteRCtx(context.Background(), path, params)
+}
+
+// DeleteRCtx make a DELETE request and response http.Response with context
+func (rqt *Requester) DeleteRCtx(ctx context.Context, path string, params map[string]string) (*http.Response, string, error) {
+	res, body, err := rqt.DeleteRHCtx(ctx, path, params, nil)
 	return res, body, err
 }
 
 // DeleteRH make a DELETE request with headers and response http.Response
 func (rqt *Requester) DeleteRH(path string, params map[string]string, headers map[string]string) (*http.Response, string, error) {
-	return rqt.DeleteRHC(path, params, headers, nil)
+	return rqt.DeleteRHCtx(context.Background(), path, params, headers)
+}
+
+// DeleteRHCtx make a DELETE request with headers and response http.Response with context
+func (rqt *Requester) DeleteRHCtx(ctx context.Context, path string, params map[string]string, headers map[string]string) (*http.Response, string, error) {
+	return rqt.DeleteRHCCtx(ctx, path, params, headers, nil)
 }
 
 // DeleteRHC make a DELETE request with headers and cookies and response http.Response
 func (rqt *Requester) DeleteRHC(path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
-	r := rqt.cloneR()
-
-	url := fmt.Sprint(rqt.config.Endpoint(), path)
-	rqt.logger.Debug("[RQT DELETE]: " + url)
-	r = r.Delete(url)
-	rqt.setupCredential(r)
-	if params != nil {
-		for key, value := range params {
-			r = r.Param(key, value)
-		}
-	}
-
-	if headers != nil {
-		for key, value := range headers {
-			r.Header.Add(key, value)
-		}
-	}
-
-	if len(cookies) > 0 {
-		for _, c := range cookies {
-			r.AddCookie(c)
-		}
-	}
-
-	res, body, errs := r.End()
-	if len(errs) > 0 {
-		return res, "", NewErrorE(rqt.logger, errs[0])
-	}
-	rqt.logger.Debug(fmt.Sprintf("[RQT DELETE-RESP]: %s %s", url, rqt.truncateLogBody(body)))
-	if res.StatusCode >= 400 {
-		return res, body, NewErrM(res.Status)
-	}
+	return rqt.DeleteRHCCtx(context.Background(), path, params, headers, cookies)
+}
 
-	return res, body, nil
+// DeleteRHCCtx make a DELETE request with headers and cookies and response http.Response with context
+func (rqt *Requester) DeleteRHCCtx(ctx context.Context, path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	return rqt.do(ctx, &request{
+		method:  http.MethodDelete,
+		path:    path,
+		params:  params,
+		headers: headers,
+		cookies: cookies,
+	})
 }
 
 func (rqt *Requester) DeleteJSONRHC(path string, jsonBody interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
-	r := rqt.cloneR()
-
-	url := fmt.Sprint(rqt.config.Endpoint(), path)
-	rqt.logger.Debug("[RQT DELETE]: " + url)
-	r = r.Delete(url)
-	rqt.setupCredential(r)
-	if jsonBody != nil {
-		r = r.Send(jsonBody)
-	}
-
-	if headers != nil {
-		for key, value := range headers {
-			r.Header.Add(key, value)
-		}
-	}
-
-	if len(cookies) > 0 {
-		for _, c := range cookies {
-			r.AddCookie(c)
-		}
-	}
-
-	res, body, errs := r.End()
-	if len(errs) > 0 {
-		for _, err := range errs {
-			rqt.logger.Debug(fmt.Sprintf("[RQT DELETE-ERR]: %s", err.Error()))
-		}
-		if res != nil {
-			return res, body, NewErrM(res.Status)
-		} else {
-			return res, body, NewErrM(errs[0].Error())
-		}
-	}
-
-	rqt.logger.Debug(fmt.Sprintf("[RQT DELETE-RESP]: %s %s", url, rqt.truncateLogBody(body)))
+	return rqt.DeleteJSONRHCCtx(context.Background(), path, jsonBody, headers, cookies)
+}
 
-	if res.StatusCode >= 400 {
-		return res, body, NewErrM(res.Status)
+func (rqt *Requester) DeleteJSONRHCCtx(ctx context.Context, path string, jsonBody interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	body, err := encodeJSON(jsonBody)
+	if err != nil {
+		return nil, "", NewErrorE(rqt.logger, err)
 	}
-	return res, body, nil
+	return rqt.do(ctx, &request{
+		method:      http.MethodDelete,
+		path:        path,
+		headers:     headers,
+		cookies:     cookies,
+		contentType: "application/json",
+		body:        body,
+	})
 }
 
 // DeleteJSONRH make a POST request with JSON body
 func (rqt *Requester) DeleteJSONRH(path string, jsonBody interface{}, headers map[string]string) (*http.Response, string, error) {
-	return rqt.DeleteJSONRHC(path, jsonBody, headers, nil)
+	return rqt.DeleteJSONRHCtx(context.Background(), path, jsonBody, headers)
+}
+
+// DeleteJSONRHCtx make a POST request with JSON body with context
+func (rqt *Requester) DeleteJSONRHCtx(ctx context.Context, path string, jsonBody interface{}, headers map[string]string) (*http.Response, string, error) {
+	return rqt.DeleteJSONRHCCtx(ctx, path, jsonBody, headers, nil)
 }
 
 func (rqt *Requester) DeleteJSONR(path string, jsonBody interface{}) (*http.Response, string, error) {
-	return rqt.DeleteJSONRH(path, jsonBody, nil)
+	return rqt.DeleteJSONRCtx(context.Background(), path, jsonBody)
+}
+
+func (rqt *Requester) DeleteJSONRCtx(ctx context.Context, path string, jsonBody interface{}) (*http.Response, string, error) {
+	return rqt.DeleteJSONRHCtx(ctx, path, jsonBody, nil)
 }
 
 // DeleteJSON make a POST request with JSON body
 func (rqt *Requester) DeleteJSON(path string, jsonBody interface{}) (string, error) {
-	_, body, err := rqt.DeleteJSONRH(path, jsonBody, nil)
+	return rqt.DeleteJSONCtx(context.Background(), path, jsonBody)
+}
+
+// DeleteJSONCtx make a POST request with JSON body with context
+func (rqt *Requester) DeleteJSONCtx(ctx context.Context, path string, jsonBody interface{}) (string, error) {
+	_, body, err := rqt.DeleteJSONRHCtx(ctx, path, jsonBody, nil)
 	return body, err
 }
 
@@ -1,6 +1,7 @@
 package pam4sdk
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -97,8 +98,8 @@ func (rqt *MockRequester) PostJSONRHC(path string, body interface{}, headers map
 }
 
 // PostFile is mock function
-func (rqt *MockRequester) PostFile(path string, filePath string, postParam string) (string, error) {
-	args := rqt.Called(path, filePath, postParam)
+func (rqt *MockRequester) PostFile(path string, filePath string, postParam string, extraData string) (string, error) {
+	args := rqt.Called(path, filePath, postParam, extraData)
 	return args.String(0), args.Error(1)
 }
 
@@ -136,3 +137,208 @@ func (rqt *MockRequester) PostRaw(path string, data interface{}, headers map[str
 	args := rqt.Called(path, data, headers)
 	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
 }
+
+func (rqt *MockRequester) PutJSON(path string, body interface{}) (string, error) {
+	args := rqt.Called(path, body)
+	return args.String(0), args.Error(1)
+}
+
+func (rqt *MockRequester) PutJSONRH(path string, body interface{}, headers map[string]string) (*http.Response, string, error) {
+	args := rqt.Called(path, body, headers)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+func (rqt *MockRequester) PutJSONRHC(path string, body interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	args := rqt.Called(path, body, headers, cookies)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+func (rqt *MockRequester) Delete(path string, params map[string]string) (string, error) {
+	args := rqt.Called(path, params)
+	return args.String(0), args.Error(1)
+}
+
+func (rqt *MockRequester) DeleteR(path string, params map[string]string) (*http.Response, string, error) {
+	args := rqt.Called(path, params)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+func (rqt *MockRequester) DeleteRH(path string, params map[string]string, headers map[string]string) (*http.Response, string, error) {
+	args := rqt.Called(path, params, headers)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+func (rqt *MockRequester) DeleteRHC(path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	args := rqt.Called(path, params, headers, cookies)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+func (rqt *MockRequester) DeleteJSON(path string, body interface{}) (string, error) {
+	args := rqt.Called(path, body)
+	return args.String(0), args.Error(1)
+}
+
+func (rqt *MockRequester) DeleteJSONR(path string, body interface{}) (*http.Response, string, error) {
+	args := rqt.Called(path, body)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+func (rqt *MockRequester) DeleteJSONRH(path string, body interface{}, headers map[string]string) (*http.Response, string, error) {
+	args := rqt.Called(path, body, headers)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+func (rqt *MockRequester) DeleteJSONRHC(path string, body interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	args := rqt.Called(path, body, headers, cookies)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// GetCtx is mock
+func (rqt *MockRequester) GetCtx(ctx context.Context, path string, params map[string]string) (string, error) {
+	args := rqt.Called(ctx, path, params)
+	return args.String(0), args.Error(1)
+}
+
+// GetRCtx is mock
+func (rqt *MockRequester) GetRCtx(ctx context.Context, path string, params map[string]string) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, params)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// GetRHCtx is mock
+func (rqt *MockRequester) GetRHCtx(ctx context.Context, path string, params map[string]string, headers map[string]string) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, params, headers)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// GetRHCCtx is mock
+func (rqt *MockRequester) GetRHCCtx(ctx context.Context, path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, params, headers, cookies)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// PostCtx is mock
+func (rqt *MockRequester) PostCtx(ctx context.Context, path string, params map[string]string) (string, error) {
+	args := rqt.Called(ctx, path, params)
+	return args.String(0), args.Error(1)
+}
+
+// PostRCtx is mock
+func (rqt *MockRequester) PostRCtx(ctx context.Context, path string, params map[string]string) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, params)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// PostRHCtx is mock
+func (rqt *MockRequester) PostRHCtx(ctx context.Context, path string, params map[string]string, headers map[string]string) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, params, headers)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// PostRHCCtx is mock
+func (rqt *MockRequester) PostRHCCtx(ctx context.Context, path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, params, headers, cookies)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// PostRawCtx is mock
+func (rqt *MockRequester) PostRawCtx(ctx context.Context, path string, data interface{}, headers map[string]string) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, data, headers)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// PostJSONCtx is mock
+func (rqt *MockRequester) PostJSONCtx(ctx context.Context, path string, body interface{}) (string, error) {
+	args := rqt.Called(ctx, path, body)
+	return args.String(0), args.Error(1)
+}
+
+// PostJSONRCtx is mock
+func (rqt *MockRequester) PostJSONRCtx(ctx context.Context, path string, body interface{}) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, body)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// PostJSONRHCtx is mock
+func (rqt *MockRequester) PostJSONRHCtx(ctx context.Context, path string, body interface{}, headers map[string]string) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, body, headers)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// PostJSONRHCCtx is mock
+func (rqt *MockRequester) PostJSONRHCCtx(ctx context.Context, path string, body interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, body, headers, cookies)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// PostFileCtx is mock
+func (rqt *MockRequester) PostFileCtx(ctx context.Context, path string, filePath string, postParam string, extraData string) (string, error) {
+	args := rqt.Called(ctx, path, filePath, postParam, extraData)
+	return args.String(0), args.Error(1)
+}
+
+// PutJSONCtx is mock
+func (rqt *MockRequester) PutJSONCtx(ctx context.Context, path string, body interface{}) (string, error) {
+	args := rqt.Called(ctx, path, body)
+	return args.String(0), args.Error(1)
+}
+
+// PutJSONRHCtx is mock
+func (rqt *MockRequester) PutJSONRHCtx(ctx context.Context, path string, body interface{}, headers map[string]string) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, body, headers)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// PutJSONRHCCtx is mock
+func (rqt *MockRequester) PutJSONRHCCtx(ctx context.Context, path string, body interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, body, headers, cookies)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// DeleteCtx is mock
+func (rqt *MockRequester) DeleteCtx(ctx context.Context, path string, params map[string]string) (string, error) {
+	args := rqt.Called(ctx, path, params)
+	return args.String(0), args.Error(1)
+}
+
+// DeleteRCtx is mock
+func (rqt *MockRequester) DeleteRCtx(ctx context.Context, path string, params map[string]string) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, params)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// DeleteRHCtx is mock
+func (rqt *MockRequester) DeleteRHCtx(ctx context.Context, path string, params map[string]string, headers map[string]string) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, params, headers)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// DeleteRHCCtx is mock
+func (rqt *MockRequester) DeleteRHCCtx(ctx context.Context, path string, params map[string]string, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, params, headers, cookies)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// DeleteJSONCtx is mock
+func (rqt *MockRequester) DeleteJSONCtx(ctx context.Context, path string, body interface{}) (string, error) {
+	args := rqt.Called(ctx, path, body)
+	return args.String(0), args.Error(1)
+}
+
+// DeleteJSONRCtx is mock
+func (rqt *MockRequester) DeleteJSONRCtx(ctx context.Context, path string, body interface{}) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, body)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// DeleteJSONRHCtx is mock
+func (rqt *MockRequester) DeleteJSONRHCtx(ctx context.Context, path string, body interface{}, headers map[string]string) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, body, headers)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
+
+// DeleteJSONRHCCtx is mock
+func (rqt *MockRequester) DeleteJSONRHCCtx(ctx context.Context, path string, body interface{}, headers map[string]string, cookies []*http.Cookie) (*http.Response, string, error) {
+	args := rqt.Called(ctx, path, body, headers, cookies)
+	return args.Get(0).(*http.Response), args.String(1), args.Error(2)
+}
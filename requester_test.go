@@ -1,9 +1,13 @@
 package pam4sdk
 
 import (
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -258,3 +262,142 @@ func (ts *RequesterTestSuite) TestPostJSON_GivenParamsAndHeadersAndCookies_Expec
 		is.Equal("OK", result)
 	}
 }
+
+func (ts *RequesterTestSuite) TestGetCtx_GivenCancelledContext_ExpectRequestAborted() {
+	is := assert.New(ts.T())
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	rqt := NewRequester(ts.requesterConfig(server.URL), NewLoggerSimple())
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := rqt.GetCtx(ctx, "/abc", nil)
+	if is.Error(err) {
+		is.Equal(context.Canceled.Error(), err.Error())
+	}
+	is.True(time.Since(start) < time.Second)
+}
+
+func (ts *RequesterTestSuite) TestPostJSONCtx_GivenDeadline_ExpectDeadlineExceeded() {
+	is := assert.New(ts.T())
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	rqt := NewRequester(ts.requesterConfig(server.URL), NewLoggerSimple())
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := rqt.PostJSONCtx(ctx, "/abc", map[string]string{"a": "abc"})
+	if is.Error(err) {
+		is.Equal(context.DeadlineExceeded.Error(), err.Error())
+	}
+}
+
+func (ts *RequesterTestSuite) TestGet_GivenZeroTimeout_ExpectNoDeadline() {
+	is := assert.New(ts.T())
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	cfg := NewCustomRequesterConfig(server.URL, "x-app-id", "x-secret", "my-app-id-1234", "my-secret-1234", 0)
+	result, err := NewRequester(cfg, NewLoggerSimple()).Get("/abc", nil)
+
+	is.NoError(err)
+	is.Equal("OK", result)
+}
+
+func (ts *RequesterTestSuite) TestGET_GivenNotFoundResponse_ExpectAPIError() {
+	is := assert.New(ts.T())
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+		rw.Write([]byte(`{"code":"NOT_FOUND","message":"campaign not found"}`))
+	}))
+	defer server.Close()
+
+	rqt := NewRequester(ts.requesterConfig(server.URL), NewLoggerSimple())
+	_, body, err := rqt.GetR("/campaigns/123", nil)
+	is.True(IsNotFound(err))
+	if e, ok := AsAPIError(err); is.True(ok) {
+		is.Equal("GET", e.Method)
+		is.Equal("/campaigns/123", e.Path)
+		is.Equal("NOT_FOUND", e.Code)
+		is.Equal(body, e.Body)
+	}
+}
+
+func (ts *RequesterTestSuite) TestGET_GivenTransport_ExpectRequestSentThroughTransport() {
+	is := assert.New(ts.T())
+	var sent *http.Request
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = req
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     "200 OK",
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader("OK")),
+			Request:    req,
+		}, nil
+	})
+
+	rqt := NewRequester(ts.requesterConfig("http://pam.test"), NewLoggerSimple(), RequesterTransport(transport))
+	result, err := rqt.Get("/abc", map[string]string{"a": "abc"})
+	if is.NoError(err) {
+		is.Equal("OK", result)
+		is.Equal("http://pam.test/abc?a=abc", sent.URL.String())
+		is.Equal("my-app-id-1234", sent.Header.Get("x-app-id"))
+	}
+}
+
+func (ts *RequesterTestSuite) TestPostFile_GivenExtraData_ExpectMultipartBody() {
+	is := assert.New(ts.T())
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		is.Equal("my-app-id-1234", req.Header.Get("x-app-id"))
+		if is.NoError(req.ParseMultipartForm(1 << 20)) {
+			is.Equal("a", req.FormValue("attrs"))
+			is.Equal("b", req.FormValue("tags"))
+			file, header, err := req.FormFile("file")
+			if is.NoError(err) {
+				content, _ := ioutil.ReadAll(file)
+				is.Equal("contacts.csv", header.Filename)
+				is.Equal("email\n", string(content))
+			}
+		}
+		rw.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "pam4sdk")
+	is.NoError(err)
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "contacts.csv")
+	is.NoError(ioutil.WriteFile(filePath, []byte("email\n"), 0600))
+
+	rqt := NewRequester(ts.requesterConfig(server.URL), NewLoggerSimple())
+	result, err := rqt.PostFile("/upload", filePath, "file", "attrs=a&&tags=b")
+	if is.NoError(err) {
+		is.Equal("OK", result)
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,191 @@
+package pam4sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Pagination is paging information returned with list responses
+type Pagination struct {
+	Page      int `json:"page"`
+	Limit     int `json:"limit"`
+	Total     int `json:"total"`
+	TotalPage int `json:"total_page"`
+}
+
+// StatusResponse is response of APIs that only report success
+type StatusResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// Product is product information returned by trends and recommends APIs
+type Product struct {
+	ID          string                 `json:"id"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	URL         string                 `json:"url"`
+	Image       string                 `json:"image"`
+	Price       float64                `json:"price"`
+	Score       float64                `json:"score"`
+	Attrs       map[string]interface{} `json:"attrs,omitempty"`
+}
+
+// ProductTrendsResponse is response of ProductTrends
+type ProductTrendsResponse struct {
+	Products []*Product `json:"products"`
+}
+
+// ProductRecommendsResponse is response of ProductRecommends
+type ProductRecommendsResponse struct {
+	AIID      string     `json:"ai_id"`
+	ContactID string     `json:"contact_id"`
+	Products  []*Product `json:"products"`
+}
+
+// AppNotification is notification message for a contact
+type AppNotification struct {
+	ID          string          `json:"id"`
+	CampaignID  string          `json:"campaign_id"`
+	Icon        string          `json:"icon"`
+	Banner      string          `json:"banner"`
+	URL         string          `json:"url"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	JSONData    json.RawMessage `json:"json_data"`
+	IsRead      bool            `json:"is_read"`
+	CreatedAt   *time.Time      `json:"created_at"`
+}
+
+// AppNotificationsResponse is response of AppNotifications
+type AppNotificationsResponse struct {
+	Items []*AppNotification `json:"items"`
+}
+
+// SegmentsCount is response of GetSegmentsCount
+type SegmentsCount struct {
+	Count int `json:"count"`
+}
+
+// SegmentResponse is segment information returned by PAM
+type SegmentResponse struct {
+	Segment
+	ID        string     `json:"id"`
+	Type      string     `json:"type"`
+	Operator  string     `json:"operator"`
+	IsCustom  bool       `json:"is_custom"`
+	CreatedAt *time.Time `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// SegmentList is response of GetSegments
+type SegmentList struct {
+	Pagination
+	Segments []*SegmentResponse `json:"data"`
+}
+
+// SegmentStat is number of contacts in a segment
+type SegmentStat struct {
+	ID    string `json:"id"`
+	Count int    `json:"count"`
+}
+
+// CampaignResponse is campaign information returned by PAM
+type CampaignResponse struct {
+	ID                 string            `json:"id"`
+	Alias              string            `json:"alias"`
+	Name               string            `json:"name"`
+	State              string            `json:"state"`
+	IsEnabled          bool              `json:"is_enabled"`
+	CampaignCategoryID string            `json:"campaign_category_id"`
+	NonExpired         bool              `json:"non_expired"`
+	Tags               []interface{}     `json:"tags"`
+	DatePushRanges     []*DatePushRanges `json:"date_push_ranges"`
+	DateWorkingRange   []string          `json:"date_working_range"`
+	Triggers           *Triggers         `json:"triggers,omitempty"`
+	CreatedAt          *time.Time        `json:"created_at"`
+	UpdatedAt          *time.Time        `json:"updated_at"`
+}
+
+// CampaignList is response of GetCampaigns
+type CampaignList struct {
+	Pagination
+	Campaigns []*CampaignResponse `json:"data"`
+}
+
+// CampaignStat is delivery statistic of a campaign
+type CampaignStat struct {
+	ID        string `json:"id"`
+	Sent      int    `json:"sent"`
+	Delivered int    `json:"delivered"`
+	Opened    int    `json:"opened"`
+	Clicked   int    `json:"clicked"`
+	Converted int    `json:"converted"`
+}
+
+// CampaignMediaReport is campaign statistic of a single media
+type CampaignMediaReport struct {
+	Media string `json:"media"`
+	CampaignStat
+}
+
+// CampaignReport is response of GetCampaignReport
+type CampaignReport struct {
+	CampaignID string                 `json:"campaign_id"`
+	Summary    *CampaignStat          `json:"summary"`
+	Media      []*CampaignMediaReport `json:"media"`
+}
+
+// ContactList is response of GetContacts and GetContactsTags
+type ContactList struct {
+	Pagination
+	Contacts []*Contact `json:"data"`
+}
+
+// ContactUploadResult is response of CreateContact
+type ContactUploadResult struct {
+	Total   int `json:"total"`
+	Success int `json:"success"`
+	Failed  int `json:"failed"`
+}
+
+// EventResult is response of SendEventCtx
+type EventResult struct {
+	// ContactID is contact PAM assigned or merged the event to
+	ContactID string `json:"contact_id"`
+	// Consent is decision taken by consent enforcement, dropped event was not sent
+	Consent ConsentDecision `json:"-"`
+}
+
+// newEventResult resolve contact id from body, then contact_id cookie set by PAM, then contact id sent.
+// Body which is not JSON is ignored because the contact id can still come from the cookie
+func newEventResult(res *http.Response, body string, contactID string) *EventResult {
+	result := &EventResult{Consent: ConsentSent}
+	json.Unmarshal([]byte(body), result)
+	if len(result.ContactID) == 0 && res != nil {
+		for _, c := range res.Cookies() {
+			if c.Name == ContactIDCookie && len(c.Value) > 0 {
+				result.ContactID = c.Value
+			}
+		}
+	}
+	if len(result.ContactID) == 0 {
+		result.ContactID = contactID
+	}
+	return result
+}
+
+// decodeResult unmarshal body into out unless request already failed
+func decodeResult(body string, err error, out interface{}) error {
+	if err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(body), out); err != nil {
+		return NewErr(err)
+	}
+	return nil
+}
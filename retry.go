@@ -0,0 +1,156 @@
+package pam4sdk
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IdempotencyKeyHeader is header that mark POST request as safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy control how Requester retry failed request.
+// Network errors, 5xx and 429 responses are retried, GET, PUT and DELETE are always
+// retryable while POST is retried only when it carries IdempotencyKeyHeader
+type RetryPolicy struct {
+	// MaxAttempts is number of attempts including the first one, less than 2 disable retry
+	MaxAttempts int
+	// InitialBackoff is wait time before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff is upper bound of wait time between attempts
+	MaxBackoff time.Duration
+	// MaxRetryAfter is longest Retry-After honored, response asking to wait longer is not retried.
+	// Zero use MaxBackoff
+	MaxRetryAfter time.Duration
+	// Multiplier is factor applied to backoff after each attempt
+	Multiplier float64
+	// Jitter is fraction of backoff that is randomized, between 0 and 1
+	Jitter float64
+}
+
+// DefaultMaxRetryAfter is longest Retry-After honored by DefaultRetryPolicy and RateLimiter
+const DefaultMaxRetryAfter = 30 * time.Second
+
+// DefaultRetryPolicy return policy with 3 attempts and exponential backoff from 200ms
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		MaxRetryAfter:  DefaultMaxRetryAfter,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// allowed return true when request with method and headers can be retried
+func (p *RetryPolicy) allowed(method string, headers http.Header) bool {
+	if p == nil || p.MaxAttempts < 2 {
+		return false
+	}
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		return len(headers.Get(IdempotencyKeyHeader)) > 0
+	}
+	return false
+}
+
+// shouldRetry return true when attempt failed with retryable error and attempts are left
+func (p *RetryPolicy) shouldRetry(attempt int, res *http.Response, err error) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	if res.StatusCode != http.StatusTooManyRequests && res.StatusCode < 500 {
+		return false
+	}
+	if wait, ok := parseRetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+		if limit := p.retryAfterLimit(); limit > 0 && wait > limit {
+			return false
+		}
+	}
+	return true
+}
+
+// retryAfterLimit return longest Retry-After honored, zero is no limit
+func (p *RetryPolicy) retryAfterLimit() time.Duration {
+	if p.MaxRetryAfter > 0 {
+		return p.MaxRetryAfter
+	}
+	return p.MaxBackoff
+}
+
+// backoff return wait time before next attempt
+func (p *RetryPolicy) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if wait, ok := parseRetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+			if limit := p.retryAfterLimit(); limit > 0 && wait > limit {
+				wait = limit
+			}
+			return wait
+		}
+	}
+
+	wait := float64(p.InitialBackoff)
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	for i := 1; i < attempt; i++ {
+		wait *= multiplier
+	}
+	if p.MaxBackoff > 0 && wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		wait = wait * (1 - jitter + 2*jitter*rand.Float64())
+	}
+	return time.Duration(wait)
+}
+
+// parseRetryAfter parse Retry-After header in seconds or HTTP date form
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if wait := t.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// sleepCtx wait for d or until ctx is done
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
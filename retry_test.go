@@ -0,0 +1,183 @@
+package pam4sdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type RetryTestSuite struct {
+	suite.Suite
+}
+
+func TestRetryTestSuite(t *testing.T) {
+	suite.Run(t, new(RetryTestSuite))
+}
+
+func (ts *RetryTestSuite) newRequester(url string, policy *RetryPolicy) *Requester {
+	cfg := NewCustomRequesterConfig(url, "x-app-id", "x-secret", "my-app-id-1234", "my-secret-1234", 2*time.Second)
+	return NewRequester(cfg, NewLoggerSimple(), RequesterRetryPolicy(policy))
+}
+
+func (ts *RetryTestSuite) fastPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+		Multiplier:     2,
+	}
+}
+
+// failingServer respond status for the first failures calls then respond OK
+func (ts *RetryTestSuite) failingServer(failures int32, status int, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(calls, 1) <= failures {
+			rw.WriteHeader(status)
+			return
+		}
+		rw.Write([]byte("OK"))
+	}))
+}
+
+func (ts *RetryTestSuite) TestGet_GivenTransient5xx_ExpectRetriedUntilSuccess() {
+	is := assert.New(ts.T())
+	var calls int32
+	server := ts.failingServer(2, http.StatusBadGateway, &calls)
+	defer server.Close()
+
+	result, err := ts.newRequester(server.URL, ts.fastPolicy()).Get("/abc", nil)
+	if is.NoError(err) {
+		is.Equal("OK", result)
+		is.Equal(int32(3), atomic.LoadInt32(&calls))
+	}
+}
+
+func (ts *RetryTestSuite) TestGet_GivenPersistent5xx_ExpectStopAtMaxAttempts() {
+	is := assert.New(ts.T())
+	var calls int32
+	server := ts.failingServer(10, http.StatusServiceUnavailable, &calls)
+	defer server.Close()
+
+	_, err := ts.newRequester(server.URL, ts.fastPolicy()).Get("/abc", nil)
+	is.True(IsRetryable(err))
+	is.Equal(int32(3), atomic.LoadInt32(&calls))
+}
+
+func (ts *RetryTestSuite) TestGet_Given4xx_ExpectNoRetry() {
+	is := assert.New(ts.T())
+	var calls int32
+	server := ts.failingServer(10, http.StatusBadRequest, &calls)
+	defer server.Close()
+
+	_, err := ts.newRequester(server.URL, ts.fastPolicy()).Get("/abc", nil)
+	is.Error(err)
+	is.Equal(int32(1), atomic.LoadInt32(&calls))
+}
+
+func (ts *RetryTestSuite) TestPostJSON_GivenNoIdempotencyKey_ExpectNoRetry() {
+	is := assert.New(ts.T())
+	var calls int32
+	server := ts.failingServer(1, http.StatusBadGateway, &calls)
+	defer server.Close()
+
+	_, err := ts.newRequester(server.URL, ts.fastPolicy()).PostJSON("/abc", map[string]string{"a": "abc"})
+	is.Error(err)
+	is.Equal(int32(1), atomic.LoadInt32(&calls))
+}
+
+func (ts *RetryTestSuite) TestPostJSON_GivenIdempotencyKey_ExpectRetried() {
+	is := assert.New(ts.T())
+	var calls int32
+	server := ts.failingServer(1, http.StatusTooManyRequests, &calls)
+	defer server.Close()
+
+	headers := map[string]string{IdempotencyKeyHeader: "transaction_123"}
+	_, result, err := ts.newRequester(server.URL, ts.fastPolicy()).PostJSONRH("/abc", map[string]string{"a": "abc"}, headers)
+	if is.NoError(err) {
+		is.Equal("OK", result)
+		is.Equal(int32(2), atomic.LoadInt32(&calls))
+	}
+}
+
+func (ts *RetryTestSuite) TestGet_GivenNoPolicy_ExpectSingleAttempt() {
+	is := assert.New(ts.T())
+	var calls int32
+	server := ts.failingServer(1, http.StatusBadGateway, &calls)
+	defer server.Close()
+
+	_, err := ts.newRequester(server.URL, nil).Get("/abc", nil)
+	is.Error(err)
+	is.Equal(int32(1), atomic.LoadInt32(&calls))
+}
+
+func (ts *RetryTestSuite) TestBackoff_GivenRetryAfter_ExpectRetryAfterHonored() {
+	is := assert.New(ts.T())
+	policy := ts.fastPolicy()
+	policy.MaxRetryAfter = 5 * time.Second
+	res := &http.Response{Header: http.Header{}}
+	res.Header.Set("Retry-After", "3")
+
+	is.Equal(3*time.Second, policy.backoff(1, res))
+}
+
+func (ts *RetryTestSuite) TestBackoff_GivenRetryAfterAboveLimit_ExpectNoRetryAndWaitCapped() {
+	is := assert.New(ts.T())
+	policy := DefaultRetryPolicy()
+	res := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	res.Header.Set("Retry-After", "86400")
+
+	is.False(policy.shouldRetry(1, res, nil))
+	is.Equal(30*time.Second, policy.backoff(1, res))
+
+	policy.MaxRetryAfter = 0
+	is.Equal(5*time.Second, policy.backoff(1, res))
+}
+
+func (ts *RetryTestSuite) TestGet_GivenLongRetryAfter_ExpectResponseReturnedWithoutWaiting() {
+	is := assert.New(ts.T())
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		rw.Header().Set("Retry-After", "86400")
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	_, err := ts.newRequester(server.URL, ts.fastPolicy()).Get("/abc", nil)
+
+	is.Error(err)
+	is.Equal(int32(1), atomic.LoadInt32(&calls))
+	is.True(time.Since(start) < time.Second)
+}
+
+func (ts *RetryTestSuite) TestBackoff_GivenAttempts_ExpectExponentialWithCap() {
+	is := assert.New(ts.T())
+	policy := &RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     300 * time.Millisecond,
+		Multiplier:     2,
+	}
+
+	is.Equal(100*time.Millisecond, policy.backoff(1, nil))
+	is.Equal(200*time.Millisecond, policy.backoff(2, nil))
+	is.Equal(300*time.Millisecond, policy.backoff(3, nil))
+}
+
+func (ts *RetryTestSuite) TestParseRetryAfter_GivenHTTPDate_ExpectDuration() {
+	is := assert.New(ts.T())
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	wait, ok := parseRetryAfter(now.Add(5*time.Second).Format(http.TimeFormat), now)
+	is.True(ok)
+	is.Equal(5*time.Second, wait)
+
+	_, ok = parseRetryAfter("soon", now)
+	is.False(ok)
+}
@@ -1,59 +1,101 @@
 package pam4sdk
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 )
 
 // ISdk is interface for PAM client
 type ISdk interface {
 	SendEvent(contactID string, campaignID string, tracker *Tracker) (string, error)
+	SendEventCtx(ctx context.Context, contactID string, campaignID string, tracker *Tracker) (*EventResult, string, error)
 	ProductTrends(limit int) (string, error)
+	ProductTrendsCtx(ctx context.Context, limit int) (*ProductTrendsResponse, string, error)
 	ProductRecommends(aiID string, contactID string, productID int) (string, error)
+	ProductRecommendsCtx(ctx context.Context, aiID string, contactID string, productID int) (*ProductRecommendsResponse, string, error)
 	AppNotifications(contactID string, mediaAlias string, mediaValue string) (string, error)
+	AppNotificationsCtx(ctx context.Context, contactID string, mediaAlias string, mediaValue string) (*AppNotificationsResponse, string, error)
 
 	// Segments
 	GetSegmentsCount() (string, error)
+	GetSegmentsCountCtx(ctx context.Context) (*SegmentsCount, string, error)
 	GetSegments(q string, page int, limit int) (string, error)
+	GetSegmentsCtx(ctx context.Context, q string, page int, limit int) (*SegmentList, string, error)
 	GetSegmentsStats(segmentIDs []string) (string, error)
+	GetSegmentsStatsCtx(ctx context.Context, segmentIDs []string) ([]*SegmentStat, string, error)
 	GetSegmentByID(segmentID string) (string, error)
+	GetSegmentByIDCtx(ctx context.Context, segmentID string) (*SegmentResponse, string, error)
 
 	CreateSegment(body *Segment) (string, error)
+	CreateSegmentCtx(ctx context.Context, body *Segment) (*SegmentResponse, string, error)
 	UpdateSegment(segmentID string, body *Segment) (string, error)
+	UpdateSegmentCtx(ctx context.Context, segmentID string, body *Segment) (*SegmentResponse, string, error)
 
 	DeleteSegment(segmentID string) (string, error)
+	DeleteSegmentCtx(ctx context.Context, segmentID string) (*StatusResponse, string, error)
 
 	// Campaigns
 	CreateCampaign(body *CampaignPostBody) (string, error)
+	CreateCampaignCtx(ctx context.Context, body *CampaignPostBody) (*CampaignResponse, string, error)
 	UpdateCampaign(id string, body *CampaignUpdateBody) (string, error)
+	UpdateCampaignCtx(ctx context.Context, id string, body *CampaignUpdateBody) (*CampaignResponse, string, error)
 	GetCampaigns(q, aliases string, ids []string, page, limit string) (string, error)
+	GetCampaignsCtx(ctx context.Context, q, aliases string, ids []string, page, limit string) (*CampaignList, string, error)
+	FindCampaigns(query *CampaignQuery) (string, error)
+	FindCampaignsCtx(ctx context.Context, query *CampaignQuery) (*CampaignList, string, error)
 	UpdateCampaignTrigger(id string, body *CampaignTriger) (string, error)
+	UpdateCampaignTriggerCtx(ctx context.Context, id string, body *CampaignTriger) (*CampaignResponse, string, error)
 	GetCampaignsStats(campaignIDs []string) (string, error)
+	GetCampaignsStatsCtx(ctx context.Context, campaignIDs []string) ([]*CampaignStat, string, error)
 	GetCampaignDetail(campaignID string) (string, error)
+	GetCampaignDetailCtx(ctx context.Context, campaignID string) (*CampaignResponse, string, error)
 	GetCampaignDetailByAlias(alias string) (string, error)
+	GetCampaignDetailByAliasCtx(ctx context.Context, alias string) (*CampaignResponse, string, error)
 	GetCampaignReport(campaignID string) (string, error)
+	GetCampaignReportCtx(ctx context.Context, campaignID string) (*CampaignReport, string, error)
 	DeleteCampaign(campaignID string) (string, error)
+	DeleteCampaignCtx(ctx context.Context, campaignID string) (*StatusResponse, string, error)
 	GetMedia(isAll, isExcludeDisabled, MediaType string) (string, error)
+	GetMediaCtx(ctx context.Context, isAll, isExcludeDisabled, MediaType string) ([]*MediaResMessage, string, error)
+	FindMedia(query *MediaQuery) (string, error)
+	FindMediaCtx(ctx context.Context, query *MediaQuery) ([]*MediaResMessage, string, error)
 	UpdateMessageSMS(campaignID string, body *UpdateMessageSMS) (*SMSMessageResponse, string, error)
+	UpdateMessageSMSCtx(ctx context.Context, campaignID string, body *UpdateMessageSMS) (*SMSMessageResponse, string, error)
 	UpdateMessagePushNotification(campaignID string, body *UpdateMessagePushNotification) (*PushNotificationMessageResponse, string, error)
+	UpdateMessagePushNotificationCtx(ctx context.Context, campaignID string, body *UpdateMessagePushNotification) (*PushNotificationMessageResponse, string, error)
 
 	// Contact
 	CreateContact(file string, fieldMatch string, tags string) (string, error)
+	CreateContactCtx(ctx context.Context, file string, fieldMatch string, tags string) (*ContactUploadResult, string, error)
 	CreateContactWithBody(body string) (string, error)
+	CreateContactWithBodyCtx(ctx context.Context, body string) (*Contact, string, error)
 	UpdateContactAttr(contactID string, body *Contact) (string, error)
+	UpdateContactAttrCtx(ctx context.Context, contactID string, body *Contact) (*Contact, string, error)
 	GetContacts(q string, field string, page, limit string) (string, error)
+	GetContactsCtx(ctx context.Context, q string, field string, page, limit string) (*ContactList, string, error)
+	FindContacts(query *ContactQuery) (string, error)
+	FindContactsCtx(ctx context.Context, query *ContactQuery) (*ContactList, string, error)
 	DeleteTagsByContacts(body *ContactsTags) (string, error)
+	DeleteTagsByContactsCtx(ctx context.Context, body *ContactsTags) (*StatusResponse, string, error)
 	AddTagsByContacts(body *ContactsTags) (string, error)
+	AddTagsByContactsCtx(ctx context.Context, body *ContactsTags) (*StatusResponse, string, error)
 	GetContactsTags(tags string, searchKeyword string, page, limit string) (string, error)
+	GetContactsTagsCtx(ctx context.Context, tags string, searchKeyword string, page, limit string) (*ContactList, string, error)
 }
 
-// Sdk is struct for PAM client
+// Sdk is struct for PAM client, it is safe for concurrent use by multiple goroutines
+// and trackers passed to SendEvent are never modified
 type Sdk struct {
-	connect *RequestLogger
-	cms     *RequestLogger
+	connect  *RequestLogger
+	cms      *RequestLogger
+	tracer   Tracer
+	metrics  MetricsCollector
+	contacts ContactStore
+	consent  ConsentConfig
+	pii      PIIRules
 }
 
 // RequestLogger is struct for request and logger
@@ -64,97 +106,200 @@ type RequestLogger struct {
 
 // NewSdk create client using default requester and 10 seconds timeout
 func NewSdk(baseURL string, appID string, appSecret string) *Sdk {
-	sdk := &SDKConnector{baseURL, appID, appSecret, 10 * time.Second}
+	sdk := &SDKConnector{baseURL, appID, appSecret, DefaultRequestTimeout}
 	return NewSdkT(sdk, nil)
 }
 
-// NewSdkT create client using default requester with specify timeout
+// NewSdkT create client using default requester with specify timeout, nil connector is left not configured
 func NewSdkT(connectSDK, cmsSDK *SDKConnector) *Sdk {
-	connectConfig := NewCustomRequesterConfig(
-		connectSDK.BaseURL,
-		"x-app-id",
-		"x-secret",
-		connectSDK.AppID,
-		connectSDK.AppSecret,
-		connectSDK.RequestTimeout)
-	cmsConfig := NewCustomRequesterConfig(
-		cmsSDK.BaseURL,
-		"x-app-id",
-		"x-secret",
-		cmsSDK.AppID,
-		cmsSDK.AppSecret,
-		cmsSDK.RequestTimeout)
-	logger := NewLoggerSimple()
-	conr := NewRequester(connectConfig, logger)
-	cmsr := NewRequester(cmsConfig, logger)
-	return &Sdk{
-		connect: &RequestLogger{rq: conr, logger: logger},
-		cms:     &RequestLogger{rq: cmsr, logger: logger},
-	}
+	return NewClient(WithConnect(connectSDK), WithCMS(cmsSDK))
 }
 
 // NewSdkR create new client with requester
 func NewSdkR(conRL, cmsRL *RequestLogger) *Sdk {
-	return &Sdk{conRL, cmsRL}
+	return &Sdk{connect: conRL, cms: cmsRL}
+}
+
+func (sdk *Sdk) useConnect() (*RequestLogger, error) {
+	if sdk.connect == nil {
+		return nil, &ConnectorNotConfiguredError{Connector: ConnectorConnect}
+	}
+	return sdk.connect, nil
+}
+
+func (sdk *Sdk) useCMS() (*RequestLogger, error) {
+	if sdk.cms == nil {
+		return nil, &ConnectorNotConfiguredError{Connector: ConnectorCMS}
+	}
+	return sdk.cms, nil
+}
+
+// retriesEvents return true when connect requester retry events, it implement retryingSender
+// so EventDispatcher does not retry them again
+func (sdk *Sdk) retriesEvents() bool {
+	if sdk.connect == nil {
+		return false
+	}
+	rqt, ok := sdk.connect.rq.(*Requester)
+	return ok && rqt.retry != nil && rqt.retry.MaxAttempts >= 2
 }
 
 // SendEventTransaction post tracker event to PAM
 func (sdk *Sdk) SendEventTransaction(contactID string, campaignID string, transactionID string, tracker *Tracker) (string, error) {
-	return sdk.sendEvent(contactID, campaignID, transactionID, tracker)
+	_, raw, err := sdk.sendEvent(context.Background(), contactID, campaignID, transactionID, tracker, true)
+	return raw, err
+}
+
+// SendEventTransactionCtx post tracker event to PAM with context and return contact id resolved by PAM
+func (sdk *Sdk) SendEventTransactionCtx(ctx context.Context, contactID string, campaignID string, transactionID string, tracker *Tracker) (*EventResult, string, error) {
+	return sdk.sendEvent(ctx, contactID, campaignID, transactionID, tracker, true)
 }
 
 // SendEvent post tracker event to PAM
 func (sdk *Sdk) SendEvent(contactID string, campaignID string, tracker *Tracker) (string, error) {
-	return sdk.sendEvent(contactID, campaignID, "", tracker)
+	_, raw, err := sdk.sendEvent(context.Background(), contactID, campaignID, "", tracker, true)
+	return raw, err
 }
 
-// SendEvent post tracker event to PAM
-func (sdk *Sdk) sendEvent(contactID string, campaignID string, transactionID string, tracker *Tracker) (string, error) {
+// SendEventCtx post tracker event to PAM with context and return contact id resolved by PAM
+func (sdk *Sdk) SendEventCtx(ctx context.Context, contactID string, campaignID string, tracker *Tracker) (*EventResult, string, error) {
+	return sdk.sendEvent(ctx, contactID, campaignID, "", tracker, true)
+}
 
-	sdkC := sdk.connect
+// sendEvent post tracker event to PAM. When contactID is empty and ctx carry session id it is loaded
+// from contact store and contact id resolved by PAM is saved back. Gated event is dropped or anonymized without consent
+func (sdk *Sdk) sendEvent(ctx context.Context, contactID string, campaignID string, transactionID string, tracker *Tracker, gated bool) (res *EventResult, raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "SendEvent")
+	defer func() { endSpan(span, err) }()
 
-	if tracker.FormFields == nil {
-		tracker.FormFields = make(map[string]interface{})
+	sdkC, err := sdk.useConnect()
+	if err != nil {
+		return nil, "", err
+	}
+
+	sessionID := SessionIDFromContext(ctx)
+	useStore := sdk.contacts != nil && len(sessionID) > 0
+	stored := ""
+	if len(contactID) == 0 && useStore {
+		stored, err = sdk.contacts.LoadContactID(ctx, sessionID)
+		if err != nil {
+			// Event is still sent, PAM assign a new contact to it
+			sdkC.logger.Warn(fmt.Sprintf("[PAM] cannot load contact id of session %q: %s", sessionID, err.Error()))
+		}
+		contactID = stored
+	}
+
+	decision := ConsentSent
+	if gated {
+		decision = sdk.checkConsent(ctx, sdkC.logger, contactID, campaignID, tracker)
+	}
+	span.SetAttribute("pam.consent", string(decision))
+	if decision == ConsentDropped {
+		return &EventResult{ContactID: contactID, Consent: decision}, "", nil
+	}
+
+	// Copy tracker so goroutines can share it, fields are added to the copy only
+	t := *tracker
+	t.FormFields = make(map[string]interface{}, len(tracker.FormFields)+2)
+	for key, value := range tracker.FormFields {
+		t.FormFields[key] = value
+	}
+	sentContactID := contactID
+	if decision == ConsentAnonymized {
+		anonymize(&t)
+		sentContactID = ""
 	}
 	if len(campaignID) > 0 {
-		tracker.FormFields["_campaign"] = campaignID
+		t.FormFields["_campaign"] = campaignID
 	}
 	if len(transactionID) > 0 {
-		tracker.FormFields["_transaction_id"] = transactionID
+		t.FormFields["_transaction_id"] = transactionID
 	}
 
-	js, _ := json.Marshal(tracker)
+	js, _ := json.Marshal(&t)
 	p := map[string]interface{}{}
 	json.Unmarshal([]byte(js), &p)
+	if fields, ok := p["form_fields"].(map[string]interface{}); ok && len(sdk.pii) > 0 {
+		// Rules are applied after JSON round trip so nested structs and typed maps are matched too
+		p["form_fields"] = sdk.pii.apply(fields, "")
+	}
 
 	c := []*http.Cookie{
 		&http.Cookie{
-			Name:  "contact_id",
-			Value: contactID,
+			Name:  ContactIDCookie,
+			Value: sentContactID,
 		},
 	}
-	_, body, err := sdkC.rq.PostJSONRHC("/trackers/events", p, nil, c)
+	var headers map[string]string
+	if len(transactionID) > 0 {
+		// Transaction ID make the event safe to retry
+		headers = map[string]string{IdempotencyKeyHeader: transactionID}
+	}
+	httpRes, body, err := sdkC.rq.PostJSONRHCCtx(ctx, "/trackers/events", p, headers, c)
 
 	if err != nil {
-		return "", NewErrorE(sdkC.logger, err)
+		return nil, "", NewErrorE(sdkC.logger, err)
+	}
+
+	if decision == ConsentAnonymized {
+		// Contact PAM resolved for anonymized event must not be linked back to the visitor
+		return &EventResult{ContactID: contactID, Consent: decision}, body, nil
+	}
+	res = newEventResult(httpRes, body, contactID)
+	if useStore && len(res.ContactID) > 0 && res.ContactID != stored {
+		if err := sdk.contacts.SaveContactID(ctx, sessionID, res.ContactID); err != nil {
+			sdkC.logger.Warn(fmt.Sprintf("[PAM] cannot save contact id of session %q: %s", sessionID, err.Error()))
+		}
 	}
-	return body, nil
+	return res, body, nil
 }
 
 // ProductTrends return product trendings
 func (sdk *Sdk) ProductTrends(limit int) (string, error) {
-	sdkC := sdk.connect
+	return sdk.productTrends(context.Background(), limit)
+}
+
+// ProductTrendsCtx return product trendings with context
+func (sdk *Sdk) ProductTrendsCtx(ctx context.Context, limit int) (*ProductTrendsResponse, string, error) {
+	res := &ProductTrendsResponse{}
+	raw, err := sdk.productTrends(ctx, limit)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) productTrends(ctx context.Context, limit int) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "ProductTrends")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useConnect()
+	if err != nil {
+		return "", err
+	}
 	p := map[string]string{}
 	if limit > 0 {
 		p["limit"] = fmt.Sprintf("%v", limit)
 	}
 
-	return sdkC.rq.Get("/api/products/trends", p)
+	return sdkC.rq.GetCtx(ctx, "/api/products/trends", p)
 }
 
 // ProductRecommends return product recommends
 func (sdk *Sdk) ProductRecommends(aiID string, contactID string, productID int) (string, error) {
-	sdkC := sdk.connect
+	return sdk.productRecommends(context.Background(), aiID, contactID, productID)
+}
+
+// ProductRecommendsCtx return product recommends with context
+func (sdk *Sdk) ProductRecommendsCtx(ctx context.Context, aiID string, contactID string, productID int) (*ProductRecommendsResponse, string, error) {
+	res := &ProductRecommendsResponse{}
+	raw, err := sdk.productRecommends(ctx, aiID, contactID, productID)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) productRecommends(ctx context.Context, aiID string, contactID string, productID int) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "ProductRecommends")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useConnect()
+	if err != nil {
+		return "", err
+	}
 	p := map[string]string{}
 	if len(contactID) > 0 {
 		p["contact_id"] = fmt.Sprintf("%v", contactID)
@@ -165,34 +310,85 @@ func (sdk *Sdk) ProductRecommends(aiID string, contactID string, productID int)
 	}
 
 	productRecommendsPath := fmt.Sprintf("/api/ai/%s", aiID)
+	ctx = withRoute(ctx, "/api/ai/{id}")
 
-	return sdkC.rq.Get(productRecommendsPath, p)
+	return sdkC.rq.GetCtx(ctx, productRecommendsPath, p)
 }
 
 // AppNotifications return app notifications for given contactID, mediaAlias and mediaValue
 func (sdk *Sdk) AppNotifications(contactID string, mediaAlias string, mediaValue string) (string, error) {
-	sdkC := sdk.connect
+	return sdk.appNotifications(context.Background(), contactID, mediaAlias, mediaValue)
+}
+
+// AppNotificationsCtx return app notifications for given contactID, mediaAlias and mediaValue with context
+func (sdk *Sdk) AppNotificationsCtx(ctx context.Context, contactID string, mediaAlias string, mediaValue string) (*AppNotificationsResponse, string, error) {
+	res := &AppNotificationsResponse{}
+	raw, err := sdk.appNotifications(ctx, contactID, mediaAlias, mediaValue)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) appNotifications(ctx context.Context, contactID string, mediaAlias string, mediaValue string) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "AppNotifications")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useConnect()
+	if err != nil {
+		return "", err
+	}
 	p := map[string]string{}
 	p["contact_id"] = contactID
 	p["media_alias"] = mediaAlias
-	p["media_value"] = mediaValue
+	if value, ok := sdk.pii.value(mediaAlias, mediaValue); ok {
+		p["media_value"] = value
+	}
 
 	notificationPath := fmt.Sprintf("/api/app-notifications")
 
-	return sdkC.rq.Get(notificationPath, p)
+	return sdkC.rq.GetCtx(ctx, notificationPath, p)
 }
 
 // GetSegmentsCount return number of segments amount
 func (sdk *Sdk) GetSegmentsCount() (string, error) {
-	sdkC := sdk.cms
+	return sdk.getSegmentsCount(context.Background())
+}
+
+// GetSegmentsCountCtx return number of segments amount with context
+func (sdk *Sdk) GetSegmentsCountCtx(ctx context.Context) (*SegmentsCount, string, error) {
+	res := &SegmentsCount{}
+	raw, err := sdk.getSegmentsCount(ctx)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) getSegmentsCount(ctx context.Context) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "GetSegmentsCount")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useCMS()
+	if err != nil {
+		return "", err
+	}
 	countSegments := fmt.Sprintf("/triggers/count")
 
-	return sdkC.rq.Get(countSegments, nil)
+	return sdkC.rq.GetCtx(ctx, countSegments, nil)
 }
 
 // GetSegments return list of segments
 func (sdk *Sdk) GetSegments(q string, page int, limit int) (string, error) {
-	sdkC := sdk.cms
+	return sdk.getSegments(context.Background(), q, page, limit)
+}
+
+// GetSegmentsCtx return list of segments with context
+func (sdk *Sdk) GetSegmentsCtx(ctx context.Context, q string, page int, limit int) (*SegmentList, string, error) {
+	res := &SegmentList{}
+	raw, err := sdk.getSegments(ctx, q, page, limit)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) getSegments(ctx context.Context, q string, page int, limit int) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "GetSegments")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useCMS()
+	if err != nil {
+		return "", err
+	}
 	p := map[string]string{}
 	if len(q) > 0 {
 		p["q"] = q
@@ -208,12 +404,28 @@ func (sdk *Sdk) GetSegments(q string, page int, limit int) (string, error) {
 
 	segments := fmt.Sprintf("/triggers")
 
-	return sdkC.rq.Get(segments, p)
+	return sdkC.rq.GetCtx(ctx, segments, p)
 }
 
 // GetSegmentsStats return number of customer in segments amount
 func (sdk *Sdk) GetSegmentsStats(segmentIDs []string) (string, error) {
-	sdkC := sdk.connect
+	return sdk.getSegmentsStats(context.Background(), segmentIDs)
+}
+
+// GetSegmentsStatsCtx return number of customer in segments amount with context
+func (sdk *Sdk) GetSegmentsStatsCtx(ctx context.Context, segmentIDs []string) ([]*SegmentStat, string, error) {
+	var res []*SegmentStat
+	raw, err := sdk.getSegmentsStats(ctx, segmentIDs)
+	return res, raw, decodeResult(raw, err, &res)
+}
+
+func (sdk *Sdk) getSegmentsStats(ctx context.Context, segmentIDs []string) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "GetSegmentsStats")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useConnect()
+	if err != nil {
+		return "", err
+	}
 	p := map[string]string{}
 	if len(segmentIDs) > 0 {
 		p["id"] = strings.Join(segmentIDs, ",")
@@ -221,96 +433,235 @@ func (sdk *Sdk) GetSegmentsStats(segmentIDs []string) (string, error) {
 
 	segmentStat := fmt.Sprintf("/api/triggers/stat")
 
-	return sdkC.rq.Get(segmentStat, p)
+	return sdkC.rq.GetCtx(ctx, segmentStat, p)
 }
 
 // GetSegmentByID return segment info by segment ID
 func (sdk *Sdk) GetSegmentByID(segmentID string) (string, error) {
-	sdkC := sdk.cms
+	return sdk.getSegmentByID(context.Background(), segmentID)
+}
+
+// GetSegmentByIDCtx return segment info by segment ID with context
+func (sdk *Sdk) GetSegmentByIDCtx(ctx context.Context, segmentID string) (*SegmentResponse, string, error) {
+	res := &SegmentResponse{}
+	raw, err := sdk.getSegmentByID(ctx, segmentID)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) getSegmentByID(ctx context.Context, segmentID string) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "GetSegmentByID")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useCMS()
+	if err != nil {
+		return "", err
+	}
 	segmentByID := fmt.Sprintf("/triggers/%s", segmentID)
+	ctx = withRoute(ctx, "/triggers/{id}")
 
-	return sdkC.rq.Get(segmentByID, nil)
+	return sdkC.rq.GetCtx(ctx, segmentByID, nil)
 }
 
 // CreateSegment create segment
 func (sdk *Sdk) CreateSegment(body *Segment) (string, error) {
-	sdkC := sdk.cms
+	return sdk.createSegment(context.Background(), body)
+}
+
+// CreateSegmentCtx create segment with context
+func (sdk *Sdk) CreateSegmentCtx(ctx context.Context, body *Segment) (*SegmentResponse, string, error) {
+	res := &SegmentResponse{}
+	raw, err := sdk.createSegment(ctx, body)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) createSegment(ctx context.Context, body *Segment) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "CreateSegment")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useCMS()
+	if err != nil {
+		return "", err
+	}
 	createSegment := fmt.Sprintf("/triggers")
 
-	return sdkC.rq.PostJSON(createSegment, body)
+	return sdkC.rq.PostJSONCtx(ctx, createSegment, body)
 }
 
 // UpdateSegment update segment by id
 func (sdk *Sdk) UpdateSegment(segmentID string, body *Segment) (string, error) {
-	sdkC := sdk.cms
+	return sdk.updateSegment(context.Background(), segmentID, body)
+}
+
+// UpdateSegmentCtx update segment by id with context
+func (sdk *Sdk) UpdateSegmentCtx(ctx context.Context, segmentID string, body *Segment) (*SegmentResponse, string, error) {
+	res := &SegmentResponse{}
+	raw, err := sdk.updateSegment(ctx, segmentID, body)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) updateSegment(ctx context.Context, segmentID string, body *Segment) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "UpdateSegment")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useCMS()
+	if err != nil {
+		return "", err
+	}
 	updateSegment := fmt.Sprintf("/triggers/%s", segmentID)
+	ctx = withRoute(ctx, "/triggers/{id}")
 
-	return sdkC.rq.PutJSON(updateSegment, body)
+	return sdkC.rq.PutJSONCtx(ctx, updateSegment, body)
 }
 
 // DeleteSegment delete segment by id
 func (sdk *Sdk) DeleteSegment(segmentID string) (string, error) {
-	sdkC := sdk.cms
+	return sdk.deleteSegment(context.Background(), segmentID)
+}
+
+// DeleteSegmentCtx delete segment by id with context
+func (sdk *Sdk) DeleteSegmentCtx(ctx context.Context, segmentID string) (*StatusResponse, string, error) {
+	res := &StatusResponse{}
+	raw, err := sdk.deleteSegment(ctx, segmentID)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) deleteSegment(ctx context.Context, segmentID string) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "DeleteSegment")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useCMS()
+	if err != nil {
+		return "", err
+	}
 	deleteSegment := fmt.Sprintf("/triggers/%s", segmentID)
+	ctx = withRoute(ctx, "/triggers/{id}")
 
-	return sdkC.rq.Delete(deleteSegment, nil)
+	return sdkC.rq.DeleteCtx(ctx, deleteSegment, nil)
 }
 
 // CreateCampaign create campaign
 func (sdk *Sdk) CreateCampaign(body *CampaignPostBody) (string, error) {
-	sdkC := sdk.cms
+	return sdk.createCampaign(context.Background(), body)
+}
 
-	return sdkC.rq.PostJSON("/campaigns", body)
+// CreateCampaignCtx create campaign with context
+func (sdk *Sdk) CreateCampaignCtx(ctx context.Context, body *CampaignPostBody) (*CampaignResponse, string, error) {
+	res := &CampaignResponse{}
+	raw, err := sdk.createCampaign(ctx, body)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) createCampaign(ctx context.Context, body *CampaignPostBody) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "CreateCampaign")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useCMS()
+	if err != nil {
+		return "", err
+	}
+
+	return sdkC.rq.PostJSONCtx(ctx, "/campaigns", body)
 }
 
 // UpdateCampaign update campaign by id
 func (sdk *Sdk) UpdateCampaign(id string, body *CampaignUpdateBody) (string, error) {
-	sdkC := sdk.cms
+	return sdk.updateCampaign(context.Background(), id, body)
+}
+
+// UpdateCampaignCtx update campaign by id with context
+func (sdk *Sdk) UpdateCampaignCtx(ctx context.Context, id string, body *CampaignUpdateBody) (*CampaignResponse, string, error) {
+	res := &CampaignResponse{}
+	raw, err := sdk.updateCampaign(ctx, id, body)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) updateCampaign(ctx context.Context, id string, body *CampaignUpdateBody) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "UpdateCampaign")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useCMS()
+	if err != nil {
+		return "", err
+	}
 	endpoint := fmt.Sprintf("/campaigns/%s", id)
+	ctx = withRoute(ctx, "/campaigns/{id}")
 
-	return sdkC.rq.PutJSON(endpoint, body)
+	return sdkC.rq.PutJSONCtx(ctx, endpoint, body)
 }
 
 // GetCampaigns return list of campaigns
 func (sdk *Sdk) GetCampaigns(q, aliases string, ids []string, page, limit string) (string, error) {
-	sdkC := sdk.cms
-	p := map[string]string{}
-	if len(q) > 0 {
-		p["q"] = q
-	}
+	return sdk.findCampaigns(context.Background(), campaignQuery(q, aliases, ids, page, limit))
+}
 
-	if len(aliases) > 0 {
-		p["aliases"] = aliases
-	}
+// GetCampaignsCtx return list of campaigns with context
+func (sdk *Sdk) GetCampaignsCtx(ctx context.Context, q, aliases string, ids []string, page, limit string) (*CampaignList, string, error) {
+	res := &CampaignList{}
+	raw, err := sdk.findCampaigns(ctx, campaignQuery(q, aliases, ids, page, limit))
+	return res, raw, decodeResult(raw, err, res)
+}
 
-	if page != "" {
-		p["page"] = page
-	}
+// FindCampaigns return list of campaigns matching query
+func (sdk *Sdk) FindCampaigns(query *CampaignQuery) (string, error) {
+	return sdk.findCampaigns(context.Background(), query)
+}
 
-	if limit != "" {
-		p["limit"] = limit
-	}
+// FindCampaignsCtx return list of campaigns matching query with context
+func (sdk *Sdk) FindCampaignsCtx(ctx context.Context, query *CampaignQuery) (*CampaignList, string, error) {
+	res := &CampaignList{}
+	raw, err := sdk.findCampaigns(ctx, query)
+	return res, raw, decodeResult(raw, err, res)
+}
 
-	if len(ids) > 0 {
-		p["ids"] = strings.Join(ids, ",")
+func (sdk *Sdk) findCampaigns(ctx context.Context, query *CampaignQuery) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "GetCampaigns")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useCMS()
+	if err != nil {
+		return "", err
 	}
 
-	campaigns := fmt.Sprintf("/campaigns")
-
-	return sdkC.rq.Get(campaigns, p)
+	return sdkC.rq.GetCtx(ctx, "/campaigns", query.params())
 }
 
 // UpdateCampaignTrigger update segment in campaign
 func (sdk *Sdk) UpdateCampaignTrigger(id string, body *CampaignTriger) (string, error) {
-	sdkC := sdk.cms
+	return sdk.updateCampaignTrigger(context.Background(), id, body)
+}
+
+// UpdateCampaignTriggerCtx update segment in campaign with context
+func (sdk *Sdk) UpdateCampaignTriggerCtx(ctx context.Context, id string, body *CampaignTriger) (*CampaignResponse, string, error) {
+	res := &CampaignResponse{}
+	raw, err := sdk.updateCampaignTrigger(ctx, id, body)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) updateCampaignTrigger(ctx context.Context, id string, body *CampaignTriger) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "UpdateCampaignTrigger")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useCMS()
+	if err != nil {
+		return "", err
+	}
 	endpoint := fmt.Sprintf("/campaigns/%s/triggers", id)
+	ctx = withRoute(ctx, "/campaigns/{id}/triggers")
 
-	return sdkC.rq.PutJSON(endpoint, body)
+	return sdkC.rq.PutJSONCtx(ctx, endpoint, body)
 }
 
 // GetCampaignsStats return number of campaign in campaigns amount
 func (sdk *Sdk) GetCampaignsStats(campaignIDs []string) (string, error) {
-	sdkC := sdk.connect
+	return sdk.getCampaignsStats(context.Background(), campaignIDs)
+}
+
+// GetCampaignsStatsCtx return number of campaign in campaigns amount with context
+func (sdk *Sdk) GetCampaignsStatsCtx(ctx context.Context, campaignIDs []string) ([]*CampaignStat, string, error) {
+	var res []*CampaignStat
+	raw, err := sdk.getCampaignsStats(ctx, campaignIDs)
+	return res, raw, decodeResult(raw, err, &res)
+}
+
+func (sdk *Sdk) getCampaignsStats(ctx context.Context, campaignIDs []string) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "GetCampaignsStats")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useConnect()
+	if err != nil {
+		return "", err
+	}
 	p := map[string]string{}
 	if len(campaignIDs) > 0 {
 		p["id"] = strings.Join(campaignIDs, ",")
@@ -318,115 +669,337 @@ func (sdk *Sdk) GetCampaignsStats(campaignIDs []string) (string, error) {
 
 	campaignStat := fmt.Sprintf("/api/campaigns/stat")
 
-	return sdkC.rq.Get(campaignStat, p)
+	return sdkC.rq.GetCtx(ctx, campaignStat, p)
 }
 
 // GetCampaignDetail return detail of Campaign
 func (sdk *Sdk) GetCampaignDetail(campaignID string) (string, error) {
-	sdkC := sdk.cms
+	return sdk.getCampaignDetail(context.Background(), campaignID)
+}
+
+// GetCampaignDetailCtx return detail of Campaign with context
+func (sdk *Sdk) GetCampaignDetailCtx(ctx context.Context, campaignID string) (*CampaignResponse, string, error) {
+	res := &CampaignResponse{}
+	raw, err := sdk.getCampaignDetail(ctx, campaignID)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) getCampaignDetail(ctx context.Context, campaignID string) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "GetCampaignDetail")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useCMS()
+	if err != nil {
+		return "", err
+	}
 	campaigns := fmt.Sprintf("/campaigns/%s", campaignID)
+	ctx = withRoute(ctx, "/campaigns/{id}")
 
-	return sdkC.rq.Get(campaigns, nil)
+	return sdkC.rq.GetCtx(ctx, campaigns, nil)
 }
 
 // GetCampaignDetailByAlias return detail of Campaign
 func (sdk *Sdk) GetCampaignDetailByAlias(alias string) (string, error) {
-	sdkC := sdk.cms
+	return sdk.getCampaignDetailByAlias(context.Background(), alias)
+}
+
+// GetCampaignDetailByAliasCtx return detail of Campaign with context
+func (sdk *Sdk) GetCampaignDetailByAliasCtx(ctx context.Context, alias string) (*CampaignResponse, string, error) {
+	res := &CampaignResponse{}
+	raw, err := sdk.getCampaignDetailByAlias(ctx, alias)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) getCampaignDetailByAlias(ctx context.Context, alias string) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "GetCampaignDetailByAlias")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useCMS()
+	if err != nil {
+		return "", err
+	}
 	campaigns := fmt.Sprintf("/campaigns/aliases/%s", alias)
+	ctx = withRoute(ctx, "/campaigns/aliases/{alias}")
 
-	return sdkC.rq.Get(campaigns, nil)
+	return sdkC.rq.GetCtx(ctx, campaigns, nil)
 }
 
 // GetCampaignReport return report of Campaign
 func (sdk *Sdk) GetCampaignReport(campaignID string) (string, error) {
-	sdkC := sdk.connect
+	return sdk.getCampaignReport(context.Background(), campaignID)
+}
+
+// GetCampaignReportCtx return report of Campaign with context
+func (sdk *Sdk) GetCampaignReportCtx(ctx context.Context, campaignID string) (*CampaignReport, string, error) {
+	res := &CampaignReport{}
+	raw, err := sdk.getCampaignReport(ctx, campaignID)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) getCampaignReport(ctx context.Context, campaignID string) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "GetCampaignReport")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useConnect()
+	if err != nil {
+		return "", err
+	}
 	campaigns := fmt.Sprintf("/api/reports/campaigns/%s", campaignID)
+	ctx = withRoute(ctx, "/api/reports/campaigns/{id}")
 
-	return sdkC.rq.Get(campaigns, nil)
+	return sdkC.rq.GetCtx(ctx, campaigns, nil)
 }
 
 // DeleteCampaign delete campaign by id
 func (sdk *Sdk) DeleteCampaign(campaignID string) (string, error) {
-	sdkC := sdk.cms
+	return sdk.deleteCampaign(context.Background(), campaignID)
+}
+
+// DeleteCampaignCtx delete campaign by id with context
+func (sdk *Sdk) DeleteCampaignCtx(ctx context.Context, campaignID string) (*StatusResponse, string, error) {
+	res := &StatusResponse{}
+	raw, err := sdk.deleteCampaign(ctx, campaignID)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) deleteCampaign(ctx context.Context, campaignID string) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "DeleteCampaign")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useCMS()
+	if err != nil {
+		return "", err
+	}
 	endpoint := fmt.Sprintf("/campaigns/%s", campaignID)
+	ctx = withRoute(ctx, "/campaigns/{id}")
 
-	return sdkC.rq.Delete(endpoint, nil)
+	return sdkC.rq.DeleteCtx(ctx, endpoint, nil)
 }
 
 // CreateContact return nil when create success
 func (sdk *Sdk) CreateContact(filePath, attrs, tags string) (string, error) {
-	sdkC := sdk.connect
+	return sdk.createContact(context.Background(), filePath, attrs, tags)
+}
+
+// CreateContactCtx return nil when create success with context
+func (sdk *Sdk) CreateContactCtx(ctx context.Context, filePath, attrs, tags string) (*ContactUploadResult, string, error) {
+	res := &ContactUploadResult{}
+	raw, err := sdk.createContact(ctx, filePath, attrs, tags)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) createContact(ctx context.Context, filePath, attrs, tags string) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "CreateContact")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useConnect()
+	if err != nil {
+		return "", err
+	}
 	extraData := fmt.Sprintf(`attrs=%s&&tags=%s`, attrs, tags)
 
-	return sdkC.rq.PostFile("/api/contacts/upload", filePath, "file", extraData)
+	return sdkC.rq.PostFileCtx(ctx, "/api/contacts/upload", filePath, "file", extraData)
 }
 
 // CreateContactWithBody is create contact api
 func (sdk *Sdk) CreateContactWithBody(body string) (string, error) {
-	sdkC := sdk.connect
+	return sdk.createContactWithBody(context.Background(), body)
+}
+
+// CreateContactWithBodyCtx is create contact api with context
+func (sdk *Sdk) CreateContactWithBodyCtx(ctx context.Context, body string) (*Contact, string, error) {
+	res := &Contact{}
+	raw, err := sdk.createContactWithBody(ctx, body)
+	return res, raw, decodeResult(raw, err, res)
+}
 
-	return sdkC.rq.PostJSON("/api/contacts", body)
+func (sdk *Sdk) createContactWithBody(ctx context.Context, body string) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "CreateContactWithBody")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useConnect()
+	if err != nil {
+		return "", err
+	}
+
+	contact, err := sdk.pii.contact(body)
+	if err != nil {
+		return "", NewErrorE(sdkC.logger, err)
+	}
+	return sdkC.rq.PostJSONCtx(ctx, "/api/contacts", contact)
 }
 
 // UpdateContactAttr return contact information when update success
 func (sdk *Sdk) UpdateContactAttr(contactID string, body *Contact) (string, error) {
-	sdkC := sdk.connect
+	return sdk.updateContactAttr(context.Background(), contactID, body)
+}
+
+// UpdateContactAttrCtx return contact information when update success with context
+func (sdk *Sdk) UpdateContactAttrCtx(ctx context.Context, contactID string, body *Contact) (*Contact, string, error) {
+	res := &Contact{}
+	raw, err := sdk.updateContactAttr(ctx, contactID, body)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) updateContactAttr(ctx context.Context, contactID string, body *Contact) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "UpdateContactAttr")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useConnect()
+	if err != nil {
+		return "", err
+	}
 	updateContact := fmt.Sprintf("/api/contacts/%s", contactID)
+	ctx = withRoute(ctx, "/api/contacts/{id}")
 
-	return sdkC.rq.PutJSON(updateContact, body)
+	contact, err := sdk.pii.contact(body)
+	if err != nil {
+		return "", NewErrorE(sdkC.logger, err)
+	}
+	return sdkC.rq.PutJSONCtx(ctx, updateContact, contact)
 }
 
 // GetContacts return contact list
 func (sdk *Sdk) GetContacts(searchKeyword string, field, page, limit string) (string, error) {
-	sdkC := sdk.connect
-	params := map[string]string{
-		"q":     searchKeyword,
-		"field": field,
-		"page":  page,
-		"limit": limit,
+	return sdk.findContacts(context.Background(), contactQuery(searchKeyword, field, page, limit))
+}
+
+// GetContactsCtx return contact list with context
+func (sdk *Sdk) GetContactsCtx(ctx context.Context, searchKeyword string, field, page, limit string) (*ContactList, string, error) {
+	res := &ContactList{}
+	raw, err := sdk.findContacts(ctx, contactQuery(searchKeyword, field, page, limit))
+	return res, raw, decodeResult(raw, err, res)
+}
+
+// FindContacts return list of contacts matching query
+func (sdk *Sdk) FindContacts(query *ContactQuery) (string, error) {
+	return sdk.findContacts(context.Background(), query)
+}
+
+// FindContactsCtx return list of contacts matching query with context
+func (sdk *Sdk) FindContactsCtx(ctx context.Context, query *ContactQuery) (*ContactList, string, error) {
+	res := &ContactList{}
+	raw, err := sdk.findContacts(ctx, query)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+// findContacts search contacts, contacts having tags are searched with tag endpoint
+func (sdk *Sdk) findContacts(ctx context.Context, query *ContactQuery) (string, error) {
+	return sdk.searchContacts(ctx, query, query != nil && len(query.Tags) > 0)
+}
+
+func (sdk *Sdk) searchContacts(ctx context.Context, query *ContactQuery, byTags bool) (raw string, err error) {
+	method, path := "GetContacts", "/api/contacts"
+	if byTags {
+		method, path = "GetContactsTags", "/api/contacts/tag/multiple"
+	}
+	ctx, span := sdk.startSpan(ctx, method)
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useConnect()
+	if err != nil {
+		return "", err
 	}
 
-	return sdkC.rq.Get("/api/contacts", params)
+	return sdkC.rq.GetCtx(ctx, path, query.params())
 }
 
 // AddTagsByContacts add tag in old contact
 func (sdk *Sdk) AddTagsByContacts(body *ContactsTags) (string, error) {
-	sdkC := sdk.connect
+	return sdk.addTagsByContacts(context.Background(), body)
+}
 
-	return sdkC.rq.PostJSON("/api/contacts/tags", body)
+// AddTagsByContactsCtx add tag in old contact with context
+func (sdk *Sdk) AddTagsByContactsCtx(ctx context.Context, body *ContactsTags) (*StatusResponse, string, error) {
+	res := &StatusResponse{}
+	raw, err := sdk.addTagsByContacts(ctx, body)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) addTagsByContacts(ctx context.Context, body *ContactsTags) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "AddTagsByContacts")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useConnect()
+	if err != nil {
+		return "", err
+	}
+
+	return sdkC.rq.PostJSONCtx(ctx, "/api/contacts/tags", body)
 }
 
 // DeleteTagsByContacts return tags available
 func (sdk *Sdk) DeleteTagsByContacts(body *ContactsTags) (string, error) {
-	sdkC := sdk.connect
+	return sdk.deleteTagsByContacts(context.Background(), body)
+}
 
-	return sdkC.rq.DeleteJSON("/api/contacts/tags", body)
+// DeleteTagsByContactsCtx return tags available with context
+func (sdk *Sdk) DeleteTagsByContactsCtx(ctx context.Context, body *ContactsTags) (*StatusResponse, string, error) {
+	res := &StatusResponse{}
+	raw, err := sdk.deleteTagsByContacts(ctx, body)
+	return res, raw, decodeResult(raw, err, res)
+}
+
+func (sdk *Sdk) deleteTagsByContacts(ctx context.Context, body *ContactsTags) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "DeleteTagsByContacts")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useConnect()
+	if err != nil {
+		return "", err
+	}
+
+	return sdkC.rq.DeleteJSONCtx(ctx, "/api/contacts/tags", body)
 }
 
 // GetMedia return media list
 func (sdk *Sdk) GetMedia(isAll, isExcludeDisabled, MediaType string) (string, error) {
-	sdkC := sdk.cms
-	params := map[string]string{
-		"is_all":           isAll,
-		"exclude_disabled": isExcludeDisabled,
-		"type":             MediaType,
+	return sdk.findMedia(context.Background(), mediaQuery(isAll, isExcludeDisabled, MediaType))
+}
+
+// GetMediaCtx return media list with context
+func (sdk *Sdk) GetMediaCtx(ctx context.Context, isAll, isExcludeDisabled, MediaType string) ([]*MediaResMessage, string, error) {
+	var res []*MediaResMessage
+	raw, err := sdk.findMedia(ctx, mediaQuery(isAll, isExcludeDisabled, MediaType))
+	return res, raw, decodeResult(raw, err, &res)
+}
+
+// FindMedia return list of media matching query
+func (sdk *Sdk) FindMedia(query *MediaQuery) (string, error) {
+	return sdk.findMedia(context.Background(), query)
+}
+
+// FindMediaCtx return list of media matching query with context
+func (sdk *Sdk) FindMediaCtx(ctx context.Context, query *MediaQuery) ([]*MediaResMessage, string, error) {
+	var res []*MediaResMessage
+	raw, err := sdk.findMedia(ctx, query)
+	return res, raw, decodeResult(raw, err, &res)
+}
+
+func (sdk *Sdk) findMedia(ctx context.Context, query *MediaQuery) (raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "GetMedia")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useCMS()
+	if err != nil {
+		return "", err
 	}
 
-	return sdkC.rq.Get("/media", params)
+	return sdkC.rq.GetCtx(ctx, "/media", query.params())
 }
 
 // UpdateMessageSMS update message by media type
 func (sdk *Sdk) UpdateMessageSMS(campaignID string, body *UpdateMessageSMS) (*SMSMessageResponse, string, error) {
-	sdkC := sdk.cms
+	return sdk.UpdateMessageSMSCtx(context.Background(), campaignID, body)
+}
+
+// UpdateMessageSMSCtx update message by media type with context
+func (sdk *Sdk) UpdateMessageSMSCtx(ctx context.Context, campaignID string, body *UpdateMessageSMS) (res *SMSMessageResponse, raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "UpdateMessageSMS")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useCMS()
+	if err != nil {
+		return &SMSMessageResponse{}, "", err
+	}
 	endpoint := fmt.Sprintf("/campaigns/%s/messages/sms", campaignID)
+	ctx = withRoute(ctx, "/campaigns/{id}/messages/sms")
 
-	resultStr, err := sdkC.rq.PutJSON(endpoint, body)
+	resultStr, err := sdkC.rq.PutJSONCtx(ctx, endpoint, body)
 
 	if err != nil {
 		return &SMSMessageResponse{}, "", err
 	}
 
-	res := &SMSMessageResponse{}
+	res = &SMSMessageResponse{}
 	err = json.Unmarshal([]byte(resultStr), res)
 
 	if err != nil {
@@ -441,16 +1014,30 @@ func (sdk *Sdk) UpdateMessagePushNotification(
 	campaignID string,
 	body *UpdateMessagePushNotification,
 ) (*PushNotificationMessageResponse, string, error) {
-	sdkC := sdk.cms
+	return sdk.UpdateMessagePushNotificationCtx(context.Background(), campaignID, body)
+}
+
+// UpdateMessagePushNotificationCtx message by media type with context
+func (sdk *Sdk) UpdateMessagePushNotificationCtx(ctx context.Context,
+	campaignID string,
+	body *UpdateMessagePushNotification,
+) (res *PushNotificationMessageResponse, raw string, err error) {
+	ctx, span := sdk.startSpan(ctx, "UpdateMessagePushNotification")
+	defer func() { endSpan(span, err) }()
+	sdkC, err := sdk.useCMS()
+	if err != nil {
+		return &PushNotificationMessageResponse{}, "", err
+	}
 	endpoint := fmt.Sprintf("/campaigns/%s/messages/mobile_notification", campaignID)
+	ctx = withRoute(ctx, "/campaigns/{id}/messages/mobile_notification")
 
-	resultStr, err := sdkC.rq.PutJSON(endpoint, body)
+	resultStr, err := sdkC.rq.PutJSONCtx(ctx, endpoint, body)
 
 	if err != nil {
 		return &PushNotificationMessageResponse{}, "", err
 	}
 
-	res := &PushNotificationMessageResponse{}
+	res = &PushNotificationMessageResponse{}
 	err = json.Unmarshal([]byte(resultStr), res)
 
 	if err != nil {
@@ -462,13 +1049,12 @@ func (sdk *Sdk) UpdateMessagePushNotification(
 
 // GetContactsTags return contact list
 func (sdk *Sdk) GetContactsTags(tags string, searchKeyword string, page, limit string) (string, error) {
-	sdkC := sdk.connect
-	params := map[string]string{
-		"q":     searchKeyword,
-		"page":  page,
-		"limit": limit,
-		"tags":  tags,
-	}
+	return sdk.searchContacts(context.Background(), contactTagsQuery(tags, searchKeyword, page, limit), true)
+}
 
-	return sdkC.rq.Get("/api/contacts/tag/multiple", params)
+// GetContactsTagsCtx return contact list with context
+func (sdk *Sdk) GetContactsTagsCtx(ctx context.Context, tags string, searchKeyword string, page, limit string) (*ContactList, string, error) {
+	res := &ContactList{}
+	raw, err := sdk.searchContacts(ctx, contactTagsQuery(tags, searchKeyword, page, limit), true)
+	return res, raw, decodeResult(raw, err, res)
 }
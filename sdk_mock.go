@@ -1,6 +1,10 @@
 package pam4sdk
 
-import "github.com/3dsinteractive/testify/mock"
+import (
+	"context"
+
+	"github.com/3dsinteractive/testify/mock"
+)
 
 // MockSdk is mock for PAM sdk
 type MockSdk struct {
@@ -35,3 +39,375 @@ func (sdk *MockSdk) AppNotifications(contactID string, mediaAlias string, mediaV
 	args := sdk.Called(contactID, mediaAlias, mediaValue)
 	return args.String(0), args.Error(1)
 }
+
+// GetSegmentsCount is mock
+func (sdk *MockSdk) GetSegmentsCount() (string, error) {
+	args := sdk.Called()
+	return args.String(0), args.Error(1)
+}
+
+// GetSegments is mock
+func (sdk *MockSdk) GetSegments(q string, page int, limit int) (string, error) {
+	args := sdk.Called(q, page, limit)
+	return args.String(0), args.Error(1)
+}
+
+// GetSegmentsStats is mock
+func (sdk *MockSdk) GetSegmentsStats(segmentIDs []string) (string, error) {
+	args := sdk.Called(segmentIDs)
+	return args.String(0), args.Error(1)
+}
+
+// GetSegmentByID is mock
+func (sdk *MockSdk) GetSegmentByID(segmentID string) (string, error) {
+	args := sdk.Called(segmentID)
+	return args.String(0), args.Error(1)
+}
+
+// CreateSegment is mock
+func (sdk *MockSdk) CreateSegment(body *Segment) (string, error) {
+	args := sdk.Called(body)
+	return args.String(0), args.Error(1)
+}
+
+// UpdateSegment is mock
+func (sdk *MockSdk) UpdateSegment(segmentID string, body *Segment) (string, error) {
+	args := sdk.Called(segmentID, body)
+	return args.String(0), args.Error(1)
+}
+
+// DeleteSegment is mock
+func (sdk *MockSdk) DeleteSegment(segmentID string) (string, error) {
+	args := sdk.Called(segmentID)
+	return args.String(0), args.Error(1)
+}
+
+// CreateCampaign is mock
+func (sdk *MockSdk) CreateCampaign(body *CampaignPostBody) (string, error) {
+	args := sdk.Called(body)
+	return args.String(0), args.Error(1)
+}
+
+// UpdateCampaign is mock
+func (sdk *MockSdk) UpdateCampaign(id string, body *CampaignUpdateBody) (string, error) {
+	args := sdk.Called(id, body)
+	return args.String(0), args.Error(1)
+}
+
+// GetCampaigns is mock
+func (sdk *MockSdk) GetCampaigns(q, aliases string, ids []string, page, limit string) (string, error) {
+	args := sdk.Called(q, aliases, ids, page, limit)
+	return args.String(0), args.Error(1)
+}
+
+// FindCampaigns is mock
+func (sdk *MockSdk) FindCampaigns(query *CampaignQuery) (string, error) {
+	args := sdk.Called(query)
+	return args.String(0), args.Error(1)
+}
+
+// UpdateCampaignTrigger is mock
+func (sdk *MockSdk) UpdateCampaignTrigger(id string, body *CampaignTriger) (string, error) {
+	args := sdk.Called(id, body)
+	return args.String(0), args.Error(1)
+}
+
+// GetCampaignsStats is mock
+func (sdk *MockSdk) GetCampaignsStats(campaignIDs []string) (string, error) {
+	args := sdk.Called(campaignIDs)
+	return args.String(0), args.Error(1)
+}
+
+// GetCampaignDetail is mock
+func (sdk *MockSdk) GetCampaignDetail(campaignID string) (string, error) {
+	args := sdk.Called(campaignID)
+	return args.String(0), args.Error(1)
+}
+
+// GetCampaignDetailByAlias is mock
+func (sdk *MockSdk) GetCampaignDetailByAlias(alias string) (string, error) {
+	args := sdk.Called(alias)
+	return args.String(0), args.Error(1)
+}
+
+// GetCampaignReport is mock
+func (sdk *MockSdk) GetCampaignReport(campaignID string) (string, error) {
+	args := sdk.Called(campaignID)
+	return args.String(0), args.Error(1)
+}
+
+// DeleteCampaign is mock
+func (sdk *MockSdk) DeleteCampaign(campaignID string) (string, error) {
+	args := sdk.Called(campaignID)
+	return args.String(0), args.Error(1)
+}
+
+// GetMedia is mock
+func (sdk *MockSdk) GetMedia(isAll, isExcludeDisabled, MediaType string) (string, error) {
+	args := sdk.Called(isAll, isExcludeDisabled, MediaType)
+	return args.String(0), args.Error(1)
+}
+
+// FindMedia is mock
+func (sdk *MockSdk) FindMedia(query *MediaQuery) (string, error) {
+	args := sdk.Called(query)
+	return args.String(0), args.Error(1)
+}
+
+// UpdateMessageSMS is mock
+func (sdk *MockSdk) UpdateMessageSMS(campaignID string, body *UpdateMessageSMS) (*SMSMessageResponse, string, error) {
+	args := sdk.Called(campaignID, body)
+	return args.Get(0).(*SMSMessageResponse), args.String(1), args.Error(2)
+}
+
+// UpdateMessagePushNotification is mock
+func (sdk *MockSdk) UpdateMessagePushNotification(campaignID string, body *UpdateMessagePushNotification) (*PushNotificationMessageResponse, string, error) {
+	args := sdk.Called(campaignID, body)
+	return args.Get(0).(*PushNotificationMessageResponse), args.String(1), args.Error(2)
+}
+
+// CreateContact is mock
+func (sdk *MockSdk) CreateContact(file string, fieldMatch string, tags string) (string, error) {
+	args := sdk.Called(file, fieldMatch, tags)
+	return args.String(0), args.Error(1)
+}
+
+// CreateContactWithBody is mock
+func (sdk *MockSdk) CreateContactWithBody(body string) (string, error) {
+	args := sdk.Called(body)
+	return args.String(0), args.Error(1)
+}
+
+// UpdateContactAttr is mock
+func (sdk *MockSdk) UpdateContactAttr(contactID string, body *Contact) (string, error) {
+	args := sdk.Called(contactID, body)
+	return args.String(0), args.Error(1)
+}
+
+// GetContacts is mock
+func (sdk *MockSdk) GetContacts(q string, field string, page, limit string) (string, error) {
+	args := sdk.Called(q, field, page, limit)
+	return args.String(0), args.Error(1)
+}
+
+// FindContacts is mock
+func (sdk *MockSdk) FindContacts(query *ContactQuery) (string, error) {
+	args := sdk.Called(query)
+	return args.String(0), args.Error(1)
+}
+
+// DeleteTagsByContacts is mock
+func (sdk *MockSdk) DeleteTagsByContacts(body *ContactsTags) (string, error) {
+	args := sdk.Called(body)
+	return args.String(0), args.Error(1)
+}
+
+// AddTagsByContacts is mock
+func (sdk *MockSdk) AddTagsByContacts(body *ContactsTags) (string, error) {
+	args := sdk.Called(body)
+	return args.String(0), args.Error(1)
+}
+
+// GetContactsTags is mock
+func (sdk *MockSdk) GetContactsTags(tags string, searchKeyword string, page, limit string) (string, error) {
+	args := sdk.Called(tags, searchKeyword, page, limit)
+	return args.String(0), args.Error(1)
+}
+
+// SendEventCtx is mock
+func (sdk *MockSdk) SendEventCtx(ctx context.Context, contactID string, campaignID string, tracker *Tracker) (*EventResult, string, error) {
+	args := sdk.Called(ctx, contactID, campaignID, tracker)
+	return args.Get(0).(*EventResult), args.String(1), args.Error(2)
+}
+
+// UpdateMessageSMSCtx is mock
+func (sdk *MockSdk) UpdateMessageSMSCtx(ctx context.Context, campaignID string, body *UpdateMessageSMS) (*SMSMessageResponse, string, error) {
+	args := sdk.Called(ctx, campaignID, body)
+	return args.Get(0).(*SMSMessageResponse), args.String(1), args.Error(2)
+}
+
+// UpdateMessagePushNotificationCtx is mock
+func (sdk *MockSdk) UpdateMessagePushNotificationCtx(ctx context.Context, campaignID string, body *UpdateMessagePushNotification) (*PushNotificationMessageResponse, string, error) {
+	args := sdk.Called(ctx, campaignID, body)
+	return args.Get(0).(*PushNotificationMessageResponse), args.String(1), args.Error(2)
+}
+
+// ProductTrendsCtx is mock
+func (sdk *MockSdk) ProductTrendsCtx(ctx context.Context, limit int) (*ProductTrendsResponse, string, error) {
+	args := sdk.Called(ctx, limit)
+	return args.Get(0).(*ProductTrendsResponse), args.String(1), args.Error(2)
+}
+
+// ProductRecommendsCtx is mock
+func (sdk *MockSdk) ProductRecommendsCtx(ctx context.Context, aiID string, contactID string, productID int) (*ProductRecommendsResponse, string, error) {
+	args := sdk.Called(ctx, aiID, contactID, productID)
+	return args.Get(0).(*ProductRecommendsResponse), args.String(1), args.Error(2)
+}
+
+// AppNotificationsCtx is mock
+func (sdk *MockSdk) AppNotificationsCtx(ctx context.Context, contactID string, mediaAlias string, mediaValue string) (*AppNotificationsResponse, string, error) {
+	args := sdk.Called(ctx, contactID, mediaAlias, mediaValue)
+	return args.Get(0).(*AppNotificationsResponse), args.String(1), args.Error(2)
+}
+
+// GetSegmentsCountCtx is mock
+func (sdk *MockSdk) GetSegmentsCountCtx(ctx context.Context) (*SegmentsCount, string, error) {
+	args := sdk.Called(ctx)
+	return args.Get(0).(*SegmentsCount), args.String(1), args.Error(2)
+}
+
+// GetSegmentsCtx is mock
+func (sdk *MockSdk) GetSegmentsCtx(ctx context.Context, q string, page int, limit int) (*SegmentList, string, error) {
+	args := sdk.Called(ctx, q, page, limit)
+	return args.Get(0).(*SegmentList), args.String(1), args.Error(2)
+}
+
+// GetSegmentsStatsCtx is mock
+func (sdk *MockSdk) GetSegmentsStatsCtx(ctx context.Context, segmentIDs []string) ([]*SegmentStat, string, error) {
+	args := sdk.Called(ctx, segmentIDs)
+	return args.Get(0).([]*SegmentStat), args.String(1), args.Error(2)
+}
+
+// GetSegmentByIDCtx is mock
+func (sdk *MockSdk) GetSegmentByIDCtx(ctx context.Context, segmentID string) (*SegmentResponse, string, error) {
+	args := sdk.Called(ctx, segmentID)
+	return args.Get(0).(*SegmentResponse), args.String(1), args.Error(2)
+}
+
+// CreateSegmentCtx is mock
+func (sdk *MockSdk) CreateSegmentCtx(ctx context.Context, body *Segment) (*SegmentResponse, string, error) {
+	args := sdk.Called(ctx, body)
+	return args.Get(0).(*SegmentResponse), args.String(1), args.Error(2)
+}
+
+// UpdateSegmentCtx is mock
+func (sdk *MockSdk) UpdateSegmentCtx(ctx context.Context, segmentID string, body *Segment) (*SegmentResponse, string, error) {
+	args := sdk.Called(ctx, segmentID, body)
+	return args.Get(0).(*SegmentResponse), args.String(1), args.Error(2)
+}
+
+// DeleteSegmentCtx is mock
+func (sdk *MockSdk) DeleteSegmentCtx(ctx context.Context, segmentID string) (*StatusResponse, string, error) {
+	args := sdk.Called(ctx, segmentID)
+	return args.Get(0).(*StatusResponse), args.String(1), args.Error(2)
+}
+
+// CreateCampaignCtx is mock
+func (sdk *MockSdk) CreateCampaignCtx(ctx context.Context, body *CampaignPostBody) (*CampaignResponse, string, error) {
+	args := sdk.Called(ctx, body)
+	return args.Get(0).(*CampaignResponse), args.String(1), args.Error(2)
+}
+
+// UpdateCampaignCtx is mock
+func (sdk *MockSdk) UpdateCampaignCtx(ctx context.Context, id string, body *CampaignUpdateBody) (*CampaignResponse, string, error) {
+	args := sdk.Called(ctx, id, body)
+	return args.Get(0).(*CampaignResponse), args.String(1), args.Error(2)
+}
+
+// GetCampaignsCtx is mock
+func (sdk *MockSdk) GetCampaignsCtx(ctx context.Context, q, aliases string, ids []string, page, limit string) (*CampaignList, string, error) {
+	args := sdk.Called(ctx, q, aliases, ids, page, limit)
+	return args.Get(0).(*CampaignList), args.String(1), args.Error(2)
+}
+
+// FindCampaignsCtx is mock
+func (sdk *MockSdk) FindCampaignsCtx(ctx context.Context, query *CampaignQuery) (*CampaignList, string, error) {
+	args := sdk.Called(ctx, query)
+	return args.Get(0).(*CampaignList), args.String(1), args.Error(2)
+}
+
+// UpdateCampaignTriggerCtx is mock
+func (sdk *MockSdk) UpdateCampaignTriggerCtx(ctx context.Context, id string, body *CampaignTriger) (*CampaignResponse, string, error) {
+	args := sdk.Called(ctx, id, body)
+	return args.Get(0).(*CampaignResponse), args.String(1), args.Error(2)
+}
+
+// GetCampaignsStatsCtx is mock
+func (sdk *MockSdk) GetCampaignsStatsCtx(ctx context.Context, campaignIDs []string) ([]*CampaignStat, string, error) {
+	args := sdk.Called(ctx, campaignIDs)
+	return args.Get(0).([]*CampaignStat), args.String(1), args.Error(2)
+}
+
+// GetCampaignDetailCtx is mock
+func (sdk *MockSdk) GetCampaignDetailCtx(ctx context.Context, campaignID string) (*CampaignResponse, string, error) {
+	args := sdk.Called(ctx, campaignID)
+	return args.Get(0).(*CampaignResponse), args.String(1), args.Error(2)
+}
+
+// GetCampaignDetailByAliasCtx is mock
+func (sdk *MockSdk) GetCampaignDetailByAliasCtx(ctx context.Context, alias string) (*CampaignResponse, string, error) {
+	args := sdk.Called(ctx, alias)
+	return args.Get(0).(*CampaignResponse), args.String(1), args.Error(2)
+}
+
+// GetCampaignReportCtx is mock
+func (sdk *MockSdk) GetCampaignReportCtx(ctx context.Context, campaignID string) (*CampaignReport, string, error) {
+	args := sdk.Called(ctx, campaignID)
+	return args.Get(0).(*CampaignReport), args.String(1), args.Error(2)
+}
+
+// DeleteCampaignCtx is mock
+func (sdk *MockSdk) DeleteCampaignCtx(ctx context.Context, campaignID string) (*StatusResponse, string, error) {
+	args := sdk.Called(ctx, campaignID)
+	return args.Get(0).(*StatusResponse), args.String(1), args.Error(2)
+}
+
+// GetMediaCtx is mock
+func (sdk *MockSdk) GetMediaCtx(ctx context.Context, isAll, isExcludeDisabled, MediaType string) ([]*MediaResMessage, string, error) {
+	args := sdk.Called(ctx, isAll, isExcludeDisabled, MediaType)
+	return args.Get(0).([]*MediaResMessage), args.String(1), args.Error(2)
+}
+
+// FindMediaCtx is mock
+func (sdk *MockSdk) FindMediaCtx(ctx context.Context, query *MediaQuery) ([]*MediaResMessage, string, error) {
+	args := sdk.Called(ctx, query)
+	return args.Get(0).([]*MediaResMessage), args.String(1), args.Error(2)
+}
+
+// CreateContactCtx is mock
+func (sdk *MockSdk) CreateContactCtx(ctx context.Context, file string, fieldMatch string, tags string) (*ContactUploadResult, string, error) {
+	args := sdk.Called(ctx, file, fieldMatch, tags)
+	return args.Get(0).(*ContactUploadResult), args.String(1), args.Error(2)
+}
+
+// CreateContactWithBodyCtx is mock
+func (sdk *MockSdk) CreateContactWithBodyCtx(ctx context.Context, body string) (*Contact, string, error) {
+	args := sdk.Called(ctx, body)
+	return args.Get(0).(*Contact), args.String(1), args.Error(2)
+}
+
+// UpdateContactAttrCtx is mock
+func (sdk *MockSdk) UpdateContactAttrCtx(ctx context.Context, contactID string, body *Contact) (*Contact, string, error) {
+	args := sdk.Called(ctx, contactID, body)
+	return args.Get(0).(*Contact), args.String(1), args.Error(2)
+}
+
+// GetContactsCtx is mock
+func (sdk *MockSdk) GetContactsCtx(ctx context.Context, q string, field string, page, limit string) (*ContactList, string, error) {
+	args := sdk.Called(ctx, q, field, page, limit)
+	return args.Get(0).(*ContactList), args.String(1), args.Error(2)
+}
+
+// FindContactsCtx is mock
+func (sdk *MockSdk) FindContactsCtx(ctx context.Context, query *ContactQuery) (*ContactList, string, error) {
+	args := sdk.Called(ctx, query)
+	return args.Get(0).(*ContactList), args.String(1), args.Error(2)
+}
+
+// DeleteTagsByContactsCtx is mock
+func (sdk *MockSdk) DeleteTagsByContactsCtx(ctx context.Context, body *ContactsTags) (*StatusResponse, string, error) {
+	args := sdk.Called(ctx, body)
+	return args.Get(0).(*StatusResponse), args.String(1), args.Error(2)
+}
+
+// AddTagsByContactsCtx is mock
+func (sdk *MockSdk) AddTagsByContactsCtx(ctx context.Context, body *ContactsTags) (*StatusResponse, string, error) {
+	args := sdk.Called(ctx, body)
+	return args.Get(0).(*StatusResponse), args.String(1), args.Error(2)
+}
+
+// GetContactsTagsCtx is mock
+func (sdk *MockSdk) GetContactsTagsCtx(ctx context.Context, tags string, searchKeyword string, page, limit string) (*ContactList, string, error) {
+	args := sdk.Called(ctx, tags, searchKeyword, page, limit)
+	return args.Get(0).(*ContactList), args.String(1), args.Error(2)
+}
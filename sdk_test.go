@@ -1,10 +1,18 @@
 package pam4sdk
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/mock"
 	"github.com/3dsinteractive/testify/suite"
 )
 
@@ -47,9 +55,9 @@ func (ts *SdkTestSute) TestSendEvent_GivenEventWithParams_ExpectEventSent() {
 	}
 
 	response := &http.Response{}
-	mockRq.On("PostJSONRHC", "/trackers/events", p, map[string]string(nil), c).Return(response, "response_1234", nil)
+	mockRq.On("PostJSONRHCCtx", mock.Anything, "/trackers/events", p, map[string]string(nil), c).Return(response, "response_1234", nil)
 
-	sdk := NewSdkR(mockRq, mockLogger)
+	sdk := NewSdkR(&RequestLogger{rq: mockRq, logger: mockLogger}, nil)
 	tracker := &Tracker{
 		Event:       "event_1234",
 		PageURL:     "page url 1234",
@@ -80,12 +88,12 @@ func (ts *SdkTestSute) TestSendEvent_GivenEventWithParams_ExpectEventSent() {
 func (ts *SdkTestSute) TestProductTrends_GiveLimit_ExpectRequestSentWithLimit() {
 	mockRq := NewMockRequester()
 	mockLogger := NewMockLogger()
-	sdk := NewSdkR(mockRq, mockLogger)
+	sdk := NewSdkR(&RequestLogger{rq: mockRq, logger: mockLogger}, nil)
 
 	p := map[string]string{
 		"limit": "400",
 	}
-	mockRq.On("Get", "/api/products/trends", p).Return("response_1234", nil)
+	mockRq.On("GetCtx", mock.Anything, "/api/products/trends", p).Return("response_1234", nil)
 
 	res, err := sdk.ProductTrends(400)
 
@@ -95,3 +103,205 @@ func (ts *SdkTestSute) TestProductTrends_GiveLimit_ExpectRequestSentWithLimit()
 		mockRq.AssertExpectations(ts.T())
 	}
 }
+
+func (ts *SdkTestSute) TestGetSegmentsCtx_GivenResponse_ExpectTypedSegmentList() {
+	mockRq := NewMockRequester()
+	mockLogger := NewMockLogger()
+	sdk := NewSdkR(nil, &RequestLogger{rq: mockRq, logger: mockLogger})
+
+	p := map[string]string{
+		"page":  "2",
+		"limit": "10",
+	}
+	response := `{"page":2,"limit":10,"total":11,"data":[{"id":"segment_1","name":"VIP","alias":"vip","is_enabled":true}]}`
+	mockRq.On("GetCtx", mock.Anything, "/triggers", p).Return(response, nil)
+
+	res, raw, err := sdk.GetSegmentsCtx(context.Background(), "", 2, 10)
+
+	is := assert.New(ts.T())
+	if is.NoError(err) {
+		is.Equal(response, raw)
+		is.Equal(11, res.Total)
+		if is.Len(res.Segments, 1) {
+			is.Equal("segment_1", res.Segments[0].ID)
+			is.Equal("vip", res.Segments[0].Alias)
+			is.True(res.Segments[0].IsEnabled)
+		}
+		mockRq.AssertExpectations(ts.T())
+	}
+}
+
+func (ts *SdkTestSute) TestGetMediaCtx_GivenInvalidBody_ExpectRawBodyAndError() {
+	mockRq := NewMockRequester()
+	mockLogger := NewMockLogger()
+	sdk := NewSdkR(nil, &RequestLogger{rq: mockRq, logger: mockLogger})
+
+	p := map[string]string{
+		"is_all":           "true",
+		"exclude_disabled": "true",
+		"type":             "sms",
+	}
+	mockRq.On("GetCtx", mock.Anything, "/media", p).Return("not json", nil)
+
+	_, raw, err := sdk.GetMediaCtx(context.Background(), "true", "true", "sms")
+
+	is := assert.New(ts.T())
+	is.Error(err)
+	is.Equal("not json", raw)
+}
+
+func (ts *SdkTestSute) TestSendEventTransaction_GivenTransactionID_ExpectIdempotencyKeySent() {
+	mockRq := NewMockRequester()
+	mockLogger := NewMockLogger()
+	sdk := NewSdkR(&RequestLogger{rq: mockRq, logger: mockLogger}, nil)
+
+	headers := map[string]string{IdempotencyKeyHeader: "transaction_123"}
+	mockRq.On("PostJSONRHCCtx", mock.Anything, "/trackers/events", mock.Anything, headers, mock.Anything).Return(&http.Response{}, "response_1234", nil)
+
+	res, err := sdk.SendEventTransaction("contact_123", "", "transaction_123", &Tracker{Event: "purchase"})
+
+	is := assert.New(ts.T())
+	if is.NoError(err) {
+		is.Equal("response_1234", res)
+		mockRq.AssertExpectations(ts.T())
+	}
+}
+
+func (ts *SdkTestSute) TestSendEventCtx_GivenContactIDInBodyOrCookie_ExpectContactIDResolved() {
+	is := assert.New(ts.T())
+	cases := []struct {
+		body      string
+		setCookie string
+		expect    string
+	}{
+		{`{"contact_id":"contact_body"}`, "contact_id=contact_cookie", "contact_body"},
+		{`{}`, "contact_id=contact_cookie; Path=/", "contact_cookie"},
+		{"ok", "", "contact_123"},
+	}
+	for _, c := range cases {
+		mockRq := NewMockRequester()
+		response := &http.Response{Header: http.Header{}}
+		if len(c.setCookie) > 0 {
+			response.Header.Add("Set-Cookie", c.setCookie)
+		}
+		mockRq.On("PostJSONRHCCtx", mock.Anything, "/trackers/events", mock.Anything, mock.Anything, mock.Anything).Return(response, c.body, nil)
+		sdk := NewSdkR(&RequestLogger{rq: mockRq, logger: NewMockLogger()}, nil)
+
+		res, raw, err := sdk.SendEventCtx(context.Background(), "contact_123", "", &Tracker{Event: "view"})
+		if is.NoError(err) {
+			is.Equal(c.body, raw)
+			is.Equal(c.expect, res.ContactID)
+		}
+	}
+}
+
+func (ts *SdkTestSute) TestSendEventCtx_GivenContactStore_ExpectContactKeptPerSession() {
+	is := assert.New(ts.T())
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, _ := r.Cookie(ContactIDCookie)
+		sent = append(sent, c.Value)
+		if len(c.Value) == 0 {
+			http.SetCookie(w, &http.Cookie{Name: ContactIDCookie, Value: fmt.Sprintf("contact_%d", len(sent))})
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	store := NewMemoryContactStore()
+	sdk := NewClient(
+		WithConnect(&SDKConnector{BaseURL: server.URL, AppID: "app", AppSecret: "secret"}),
+		WithContactStore(store),
+	)
+	alice := ContextWithSessionID(context.Background(), "alice")
+	bob := ContextWithSessionID(context.Background(), "bob")
+
+	res, _, err := sdk.SendEventCtx(alice, "", "", &Tracker{Event: "view"})
+	is.NoError(err)
+	is.Equal("contact_1", res.ContactID)
+	res, _, err = sdk.SendEventCtx(bob, "", "", &Tracker{Event: "view"})
+	is.NoError(err)
+	is.Equal("contact_2", res.ContactID)
+	res, _, err = sdk.SendEventCtx(alice, "", "", &Tracker{Event: "view"})
+	is.NoError(err)
+	is.Equal("contact_1", res.ContactID)
+
+	is.Equal([]string{"", "", "contact_1"}, sent)
+	stored, _ := store.LoadContactID(context.Background(), "bob")
+	is.Equal("contact_2", stored)
+}
+
+func (ts *SdkTestSute) TestSendEventCtx_GivenContactStoreAndNoSession_ExpectContactNotShared() {
+	is := assert.New(ts.T())
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, _ := r.Cookie(ContactIDCookie)
+		sent = append(sent, c.Value)
+		http.SetCookie(w, &http.Cookie{Name: ContactIDCookie, Value: fmt.Sprintf("contact_%d", len(sent))})
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	store := NewMemoryContactStore()
+	sdk := NewClient(
+		WithConnect(&SDKConnector{BaseURL: server.URL, AppID: "app", AppSecret: "secret"}),
+		WithContactStore(store),
+	)
+
+	first, _, err := sdk.SendEventCtx(context.Background(), "", "", &Tracker{Event: "view"})
+	is.NoError(err)
+	second, _, err := sdk.SendEventCtx(context.Background(), "", "", &Tracker{Event: "view"})
+	is.NoError(err)
+
+	is.Equal("contact_1", first.ContactID)
+	is.Equal("contact_2", second.ContactID)
+	is.Equal([]string{"", ""}, sent)
+	stored, _ := store.LoadContactID(context.Background(), "")
+	is.Empty(stored)
+}
+
+func (ts *SdkTestSute) TestSendEvent_GivenConcurrentCalls_ExpectAllEventsSentWithoutRace() {
+	is := assert.New(ts.T())
+	var received int64
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		p := map[string]interface{}{}
+		if is.NoError(json.NewDecoder(req.Body).Decode(&p)) {
+			fields := p["form_fields"].(map[string]interface{})
+			is.Equal("shared", fields["custom"])
+			is.Equal("campaign_123", fields["_campaign"])
+		}
+		atomic.AddInt64(&received, 1)
+		rw.Write([]byte(`{"contact_id":"contact_123"}`))
+	}))
+	defer server.Close()
+
+	sdk := NewClient(
+		WithConnect(&SDKConnector{BaseURL: server.URL, AppID: "app-id", AppSecret: "secret"}),
+		WithTimeout(5*time.Second),
+		WithRetryPolicy(DefaultRetryPolicy()),
+		WithRateLimiters(NewAdaptiveRateLimiter(10000, 100, 100), nil),
+		WithCircuitBreakers(NewCircuitBreaker(CircuitBreakerConfig{Name: ConnectorConnect}), nil),
+	)
+	// Every goroutine share the same tracker
+	tracker := &Tracker{
+		Event:      "pageview",
+		FormFields: map[string]interface{}{"custom": "shared"},
+	}
+
+	const goroutines, calls = 20, 10
+	wg := sync.WaitGroup{}
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < calls; j++ {
+				_, err := sdk.SendEvent("contact_123", "campaign_123", tracker)
+				is.NoError(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	is.Equal(int64(goroutines*calls), atomic.LoadInt64(&received))
+	is.Equal(map[string]interface{}{"custom": "shared"}, tracker.FormFields)
+}
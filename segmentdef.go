@@ -0,0 +1,161 @@
+package pam4sdk
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SegmentDefinition is desired state of segment kept as code, segments are matched by Alias
+type SegmentDefinition struct {
+	Alias       string `json:"alias" yaml:"alias"`
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// IsEnabled is left as it is in PAM when nil, new segment is then created disabled
+	IsEnabled *bool `json:"is_enabled,omitempty" yaml:"is_enabled,omitempty"`
+	// Condition is segment expression, it is used instead of Triggers when set
+	Condition       string            `json:"condition,omitempty" yaml:"condition,omitempty"`
+	Triggers        []*SegmentTrigger `json:"triggers,omitempty" yaml:"triggers,omitempty"`
+	TriggerExcludes []string          `json:"trigger_excludes,omitempty" yaml:"trigger_excludes,omitempty"`
+	DelayAmount     string            `json:"delay_amount,omitempty" yaml:"delay_amount,omitempty"`
+	DelayUnit       string            `json:"delay_unit,omitempty" yaml:"delay_unit,omitempty"`
+	// Source is file the definition was loaded from
+	Source string `json:"-" yaml:"-"`
+}
+
+// Segment return segment body sent to PAM
+func (d *SegmentDefinition) Segment() (*Segment, error) {
+	if len(d.Alias) == 0 {
+		return nil, d.error("alias is required")
+	}
+	if len(d.Name) == 0 {
+		return nil, d.error("name is required")
+	}
+	if len(d.Condition) > 0 && len(d.Triggers) > 0 {
+		return nil, d.error("condition and triggers must not both be set")
+	}
+	if len(d.Condition) == 0 && len(d.Triggers) == 0 {
+		return nil, d.error("condition or triggers is required")
+	}
+	s := &Segment{
+		Name:            d.Name,
+		Alias:           d.Alias,
+		Description:     d.Description,
+		IsEnabled:       d.IsEnabled != nil && *d.IsEnabled,
+		Triggers:        d.Triggers,
+		TriggerExcludes: d.TriggerExcludes,
+		DelayAmount:     d.DelayAmount,
+		DelayUnit:       d.DelayUnit,
+	}
+	if len(d.Condition) > 0 {
+		if err := s.SetExpression(d.Condition); err != nil {
+			return nil, d.error(err.Error())
+		}
+	}
+	return s, nil
+}
+
+func (d *SegmentDefinition) error(reason string) error {
+	name := d.Alias
+	if len(d.Source) > 0 {
+		name = fmt.Sprintf("%s (%s)", d.Alias, d.Source)
+	}
+	return NewErrM(fmt.Sprintf("pam segment definition %s: %s", strings.TrimSpace(name), reason))
+}
+
+// LoadSegmentDefinitions read definitions from files, directories are read for *.yaml, *.yml
+// and *.json files without walking sub directories
+func LoadSegmentDefinitions(paths ...string) ([]*SegmentDefinition, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, NewErr(err)
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return nil, NewErr(err)
+		}
+		for _, e := range entries {
+			switch strings.ToLower(filepath.Ext(e.Name())) {
+			case ".yaml", ".yml", ".json":
+				if !e.IsDir() {
+					files = append(files, filepath.Join(p, e.Name()))
+				}
+			}
+		}
+	}
+	sort.Strings(files)
+
+	var defs []*SegmentDefinition
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, NewErr(err)
+		}
+		fileDefs, err := ParseSegmentDefinitions(data, f)
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, fileDefs...)
+	}
+	return defs, nil
+}
+
+// ParseSegmentDefinitions parse YAML or JSON data of source file. Data may hold a definition,
+// a list of definitions or several YAML documents, unknown fields are rejected
+func ParseSegmentDefinitions(data []byte, source string) ([]*SegmentDefinition, error) {
+	var defs []*SegmentDefinition
+	// yaml.Node.Decode ignore KnownFields, so nodes only tell the kind of each document
+	// and the strict decoder read the same document in step
+	nodes := yaml.NewDecoder(bytes.NewReader(data))
+	strict := yaml.NewDecoder(bytes.NewReader(data))
+	strict.KnownFields(true)
+	for {
+		var doc yaml.Node
+		err := nodes.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, NewErrM(fmt.Sprintf("pam segment definitions %s: %s", source, err))
+		}
+		if len(doc.Content) == 0 {
+			strict.Decode(&yaml.Node{})
+			continue
+		}
+
+		var docDefs []*SegmentDefinition
+		switch doc.Content[0].Kind {
+		case yaml.SequenceNode:
+			err = strict.Decode(&docDefs)
+		case yaml.MappingNode:
+			def := &SegmentDefinition{}
+			err = strict.Decode(def)
+			docDefs = append(docDefs, def)
+		default:
+			err = fmt.Errorf("line %d: expected definition or list of definitions", doc.Content[0].Line)
+		}
+		if err != nil {
+			return nil, NewErrM(fmt.Sprintf("pam segment definitions %s: %s", source, err))
+		}
+		for _, def := range docDefs {
+			if def == nil {
+				continue
+			}
+			def.Source = source
+			defs = append(defs, def)
+		}
+	}
+	return defs, nil
+}
@@ -0,0 +1,101 @@
+package pam4sdk
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type SegmentDefinitionTestSuite struct {
+	suite.Suite
+}
+
+func TestSegmentDefinitionTestSuite(t *testing.T) {
+	suite.Run(t, new(SegmentDefinitionTestSuite))
+}
+
+func (ts *SegmentDefinitionTestSuite) TestLoadSegmentDefinitions_GivenDirectory_ExpectYAMLAndJSONFilesLoaded() {
+	is := assert.New(ts.T())
+	dir := ts.T().TempDir()
+	yml := `alias: vip
+name: VIP
+is_enabled: true
+condition: tag in ("vip") and event("purchase", within=30d) >= 2
+delay_amount: 2
+delay_unit: day
+---
+- alias: churned
+  name: Churned
+  triggers:
+    - type: and
+      conditions:
+        - {type: attribute, attribute: last_seen_days, operator: gt, value: 90}
+`
+	is.NoError(os.WriteFile(filepath.Join(dir, "a.yaml"), []byte(yml), 0644))
+	is.NoError(os.WriteFile(filepath.Join(dir, "b.json"), []byte(`[{"alias":"new","name":"New","condition":"age < 1"}]`), 0644))
+	is.NoError(os.WriteFile(filepath.Join(dir, "README.md"), []byte(`# segments`), 0644))
+
+	defs, err := LoadSegmentDefinitions(dir)
+
+	is.NoError(err)
+	is.Len(defs, 3)
+	is.Equal("vip", defs[0].Alias)
+	is.Equal("2", defs[0].DelayAmount)
+	is.Equal(filepath.Join(dir, "a.yaml"), defs[0].Source)
+	is.Equal(boolPtr(true), defs[0].IsEnabled)
+	is.Equal("churned", defs[1].Alias)
+	is.Nil(defs[1].IsEnabled)
+	is.Equal("new", defs[2].Alias)
+
+	vip, err := defs[0].Segment()
+	is.NoError(err)
+	js, _ := json.Marshal(vip.Triggers)
+	is.JSONEq(`[{"type":"and","conditions":[
+		{"type":"tag","tag":"vip","operator":"has"},
+		{"type":"event","event":"purchase","operator":"gte","count":2,"within_amount":"30","within_unit":"day"}
+	]}]`, string(js))
+
+	churned, err := defs[1].Segment()
+	is.NoError(err)
+	expression, err := churned.Expression()
+	is.NoError(err)
+	is.Equal(`last_seen_days > 90`, expression)
+}
+
+func (ts *SegmentDefinitionTestSuite) TestSegment_GivenInvalidDefinition_ExpectErrorNamingSource() {
+	is := assert.New(ts.T())
+
+	defs, err := ParseSegmentDefinitions([]byte(`{"alias":"vip","name":"VIP","condition":"tag in (vip)"}`), "vip.json")
+	is.NoError(err)
+	_, err = defs[0].Segment()
+	is.EqualError(err, `pam segment definition vip (vip.json): pam segment expression 1:9: expected string, found identifier "vip"`)
+
+	_, err = (&SegmentDefinition{Alias: "vip"}).Segment()
+	is.EqualError(err, `pam segment definition vip: name is required`)
+
+	_, err = (&SegmentDefinition{Alias: "vip", Name: "VIP"}).Segment()
+	is.EqualError(err, `pam segment definition vip: condition or triggers is required`)
+
+	_, err = ParseSegmentDefinitions([]byte(`just text`), "bad.yaml")
+	is.EqualError(err, `pam segment definitions bad.yaml: line 1: expected definition or list of definitions`)
+}
+
+func (ts *SegmentDefinitionTestSuite) TestParseSegmentDefinitions_GivenUnknownField_ExpectError() {
+	is := assert.New(ts.T())
+	yml := `alias: vip
+name: VIP
+condition: tag in ("vip")
+---
+- alias: churned
+  name: Churned
+  conditon: last_seen_days > 90
+`
+
+	_, err := ParseSegmentDefinitions([]byte(yml), "typo.yaml")
+
+	is.EqualError(err, "pam segment definitions typo.yaml: yaml: unmarshal errors:\n  line 7: field conditon not found in type pam4sdk.SegmentDefinition")
+}
@@ -0,0 +1,243 @@
+package pam4sdk
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceParentHeader is W3C trace context header propagated to PAM
+const TraceParentHeader = "traceparent"
+
+// SpanContext identify span across process boundary
+type SpanContext struct {
+	// TraceID is 32 lower case hex characters
+	TraceID string
+	// SpanID is 16 lower case hex characters
+	SpanID string
+	// Sampled tell downstream that the trace is recorded
+	Sampled bool
+}
+
+// IsValid return true when trace id and span id are set
+func (sc SpanContext) IsValid() bool {
+	return len(sc.TraceID) == 32 && len(sc.SpanID) == 16 &&
+		sc.TraceID != strings.Repeat("0", 32) && sc.SpanID != strings.Repeat("0", 16)
+}
+
+// TraceParent return value of W3C traceparent header
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceParent parse W3C traceparent header, for example from incoming request
+func ParseTraceParent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	for _, part := range parts[:4] {
+		if _, err := hex.DecodeString(part); err != nil || strings.ToLower(part) != part {
+			return SpanContext{}, false
+		}
+	}
+	flags, _ := hex.DecodeString(parts[3])
+	sc := SpanContext{
+		TraceID: parts[1],
+		SpanID:  parts[2],
+		Sampled: flags[0]&1 == 1,
+	}
+	return sc, sc.IsValid()
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext return ctx carrying sc as parent of spans started from it
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext return span context carried by ctx
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
+
+// Span is a timed operation, it is ended exactly once
+type Span interface {
+	SetAttribute(key string, value interface{})
+	SetError(err error)
+	SpanContext() SpanContext
+	End()
+}
+
+// Tracer start spans, it must be safe for concurrent use.
+// Start return ctx carrying the new span context so spans started from it become children
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// NoopTracer record nothing, trace context already in ctx is still propagated to PAM
+type NoopTracer struct{}
+
+// Start implement Tracer
+func (NoopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	sc, _ := SpanContextFromContext(ctx)
+	return ctx, noopSpan{sc: sc}
+}
+
+type noopSpan struct {
+	sc SpanContext
+}
+
+func (noopSpan) SetAttribute(key string, value interface{}) {}
+func (noopSpan) SetError(err error)                         {}
+func (s noopSpan) SpanContext() SpanContext                 { return s.sc }
+func (noopSpan) End()                                       {}
+
+// RecordedSpan is span recorded by RecordingTracer
+type RecordedSpan struct {
+	mu           sync.Mutex
+	tracer       *RecordingTracer
+	Name         string
+	Context      SpanContext
+	ParentSpanID string
+	Attributes   map[string]interface{}
+	Err          error
+	StartTime    time.Time
+	EndTime      time.Time
+}
+
+// SetAttribute implement Span
+func (s *RecordedSpan) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Attributes[key] = value
+}
+
+// SetError implement Span
+func (s *RecordedSpan) SetError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Err = err
+}
+
+// SpanContext implement Span
+func (s *RecordedSpan) SpanContext() SpanContext {
+	return s.Context
+}
+
+// End implement Span
+func (s *RecordedSpan) End() {
+	s.mu.Lock()
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+	s.tracer.finish(s)
+}
+
+// RecordingTracer keep finished spans in memory, it is meant for tests
+type RecordingTracer struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+// NewRecordingTracer return empty RecordingTracer
+func NewRecordingTracer() *RecordingTracer {
+	return &RecordingTracer{}
+}
+
+// Start implement Tracer
+func (t *RecordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	sc := SpanContext{SpanID: newTraceID(8), Sampled: true}
+	parentSpanID := ""
+	if parent, ok := SpanContextFromContext(ctx); ok {
+		sc.TraceID = parent.TraceID
+		parentSpanID = parent.SpanID
+	} else {
+		sc.TraceID = newTraceID(16)
+	}
+	span := &RecordedSpan{
+		tracer:       t,
+		Name:         name,
+		Context:      sc,
+		ParentSpanID: parentSpanID,
+		Attributes:   map[string]interface{}{},
+		StartTime:    time.Now(),
+	}
+	return ContextWithSpanContext(ctx, sc), span
+}
+
+// Spans return finished spans in the order they ended
+func (t *RecordingTracer) Spans() []*RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*RecordedSpan{}, t.spans...)
+}
+
+func (t *RecordingTracer) finish(s *RecordedSpan) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = append(t.spans, s)
+}
+
+func newTraceID(size int) string {
+	b := make([]byte, size)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// TracingMiddleware start a span for every attempt and propagate it in traceparent header,
+// nil tracer use NoopTracer
+func TracingMiddleware(tracer Tracer) Middleware {
+	if tracer == nil {
+		tracer = NoopTracer{}
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*http.Response, string, error) {
+			ctx, span := tracer.Start(ctx, fmt.Sprintf("HTTP %s %s", req.Method, req.Route))
+			defer span.End()
+			span.SetAttribute("http.method", req.Method)
+			span.SetAttribute("http.url", req.URL)
+			span.SetAttribute("http.route", req.Route)
+			span.SetAttribute("pam.connector", req.Connector)
+			span.SetAttribute("pam.attempt", req.Attempt)
+			if sc := span.SpanContext(); sc.IsValid() {
+				req.Header.Set(TraceParentHeader, sc.TraceParent())
+			}
+
+			res, body, err := next(ctx, req)
+			if err != nil {
+				span.SetError(err)
+			} else {
+				span.SetAttribute("http.status_code", res.StatusCode)
+			}
+			return res, body, err
+		}
+	}
+}
+
+// startSpan start span of ISdk method
+func (sdk *Sdk) startSpan(ctx context.Context, method string) (context.Context, Span) {
+	tracer := sdk.tracer
+	if tracer == nil {
+		tracer = NoopTracer{}
+	}
+	return tracer.Start(ctx, "pam4sdk."+method)
+}
+
+// endSpan record err of ISdk method then end span
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.SetError(err)
+	}
+	span.End()
+}
@@ -0,0 +1,111 @@
+package pam4sdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type TracingTestSuite struct {
+	suite.Suite
+}
+
+func TestTracingTestSuite(t *testing.T) {
+	suite.Run(t, new(TracingTestSuite))
+}
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func (ts *TracingTestSuite) TestParseTraceParent_GivenHeaders_ExpectValidatedSpanContext() {
+	is := assert.New(ts.T())
+	sc, ok := ParseTraceParent(testTraceParent)
+	if is.True(ok) {
+		is.Equal("4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID)
+		is.Equal("00f067aa0ba902b7", sc.SpanID)
+		is.True(sc.Sampled)
+		is.Equal(testTraceParent, sc.TraceParent())
+	}
+
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		_, ok := ParseTraceParent(value)
+		is.False(ok, value)
+	}
+}
+
+func (ts *TracingTestSuite) TestGetSegmentByIDCtx_GivenRecordingTracer_ExpectMethodAndAttemptSpans() {
+	is := assert.New(ts.T())
+	var traceParent string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		traceParent = req.Header.Get(TraceParentHeader)
+		rw.Write([]byte(`{"id":"segment_123"}`))
+	}))
+	defer server.Close()
+
+	tracer := NewRecordingTracer()
+	sdk := NewClient(
+		WithCMS(&SDKConnector{BaseURL: server.URL, AppID: "app-id", AppSecret: "secret"}),
+		WithTracer(tracer),
+	)
+	incoming, _ := ParseTraceParent(testTraceParent)
+	ctx := ContextWithSpanContext(context.Background(), incoming)
+
+	_, _, err := sdk.GetSegmentByIDCtx(ctx, "segment_123")
+	is.NoError(err)
+
+	spans := tracer.Spans()
+	if is.Len(spans, 2) {
+		attempt, method := spans[0], spans[1]
+		is.Equal("pam4sdk.GetSegmentByID", method.Name)
+		is.Equal(incoming.TraceID, method.Context.TraceID)
+		is.Equal(incoming.SpanID, method.ParentSpanID)
+		is.NoError(method.Err)
+
+		is.Equal("HTTP GET /triggers/{id}", attempt.Name)
+		is.Equal(incoming.TraceID, attempt.Context.TraceID)
+		is.Equal(method.Context.SpanID, attempt.ParentSpanID)
+		is.Equal(http.StatusOK, attempt.Attributes["http.status_code"])
+		is.Equal(ConnectorCMS, attempt.Attributes["pam.connector"])
+		is.Equal(attempt.Context.TraceParent(), traceParent)
+	}
+}
+
+func (ts *TracingTestSuite) TestGet_GivenNoopTracerAndIncomingTrace_ExpectTraceParentPropagated() {
+	is := assert.New(ts.T())
+	var traceParent string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		traceParent = req.Header.Get(TraceParentHeader)
+		rw.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	sdk := NewClient(WithConnect(&SDKConnector{BaseURL: server.URL, AppID: "app-id", AppSecret: "secret"}))
+	incoming, _ := ParseTraceParent(testTraceParent)
+	_, _, err := sdk.ProductTrendsCtx(ContextWithSpanContext(context.Background(), incoming), 10)
+	is.NoError(err)
+	is.Equal(testTraceParent, traceParent)
+}
+
+func (ts *TracingTestSuite) TestGetMediaCtx_GivenConnectorNotConfigured_ExpectErrorRecordedInSpan() {
+	is := assert.New(ts.T())
+	tracer := NewRecordingTracer()
+	sdk := NewClient(WithTracer(tracer))
+
+	_, _, err := sdk.GetMediaCtx(context.Background(), "", "", "")
+	is.Error(err)
+	spans := tracer.Spans()
+	if is.Len(spans, 1) {
+		is.Equal("pam4sdk.GetMedia", spans[0].Name)
+		is.Equal(err, spans[0].Err)
+		is.Empty(spans[0].ParentSpanID)
+	}
+}
@@ -0,0 +1,152 @@
+package pam4sdk
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ContactIDCookie is name of cookie holding PAM contact id
+const ContactIDCookie = "contact_id"
+
+// TrackerOptions control how NewTrackerFromRequest fill Tracker
+type TrackerOptions struct {
+	// Event is event name of the tracker
+	Event string
+	// PageTitle is title of the page, requests do not carry it
+	PageTitle string
+	// TrustedProxies are IPs or CIDRs of proxies allowed to set X-Forwarded-For, X-Real-IP
+	// and X-Forwarded-Proto, entries that are neither IP nor CIDR are ignored
+	TrustedProxies []string
+	// UseReferer take page URL from Referer header when present, for trackers sent from API called by a page
+	UseReferer bool
+}
+
+// NewTrackerFromRequest return tracker filled from r and contact id read from contact_id cookie.
+// UTM fields are parsed from query string of page URL
+func NewTrackerFromRequest(r *http.Request, opts *TrackerOptions) (*Tracker, string) {
+	if opts == nil {
+		opts = &TrackerOptions{}
+	}
+	trusted := parseTrustedProxies(opts.TrustedProxies)
+
+	pageURL := requestURL(r, trusted)
+	if referer := r.Referer(); opts.UseReferer && len(referer) > 0 {
+		pageURL = referer
+	}
+	query := url.Values{}
+	rawQuery := ""
+	if u, err := url.Parse(pageURL); err == nil {
+		rawQuery = u.RawQuery
+		query = u.Query()
+	}
+
+	tracker := &Tracker{
+		Event:       opts.Event,
+		PageTitle:   opts.PageTitle,
+		PageURL:     pageURL,
+		UserAgent:   r.UserAgent(),
+		QueryString: rawQuery,
+		UTMCampaign: query.Get("utm_campaign"),
+		UTMTerm:     query.Get("utm_term"),
+		UTMContent:  query.Get("utm_content"),
+		UTMMedium:   query.Get("utm_medium"),
+		UTMSource:   query.Get("utm_source"),
+		IPAddress:   clientIP(r, trusted),
+	}
+
+	contactID := ""
+	if c, err := r.Cookie(ContactIDCookie); err == nil {
+		contactID = c.Value
+	}
+	return tracker, contactID
+}
+
+func parseTrustedProxies(proxies []string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				continue
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		if _, n, err := net.ParseCIDR(p); err == nil {
+			nets = append(nets, n)
+		}
+	}
+	return nets
+}
+
+func isTrusted(ip net.IP, trusted []*net.IPNet) bool {
+	for _, n := range trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP return IP of the peer which connected to the server
+func remoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+// clientIP return IP of the client, forwarded headers are used only when they were set by trusted proxies.
+// X-Forwarded-For is walked from the right and the first untrusted address is the client
+func clientIP(r *http.Request, trusted []*net.IPNet) string {
+	ip := remoteIP(r)
+	if ip == nil {
+		return ""
+	}
+	if !isTrusted(ip, trusted) {
+		return ip.String()
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := net.ParseIP(strings.TrimSpace(hops[i]))
+			if hop == nil {
+				// Garbage can be written by anyone, keep the last address we trust
+				break
+			}
+			ip = hop
+			if !isTrusted(hop, trusted) {
+				break
+			}
+		}
+		return ip.String()
+	}
+	if realIP := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); realIP != nil {
+		return realIP.String()
+	}
+	return ip.String()
+}
+
+// requestURL return absolute URL of r, scheme is taken from X-Forwarded-Proto when peer is trusted
+func requestURL(r *http.Request, trusted []*net.IPNet) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if ip := remoteIP(r); ip != nil && isTrusted(ip, trusted) {
+		if proto := strings.ToLower(r.Header.Get("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
@@ -0,0 +1,101 @@
+package pam4sdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/3dsinteractive/testify/assert"
+	"github.com/3dsinteractive/testify/suite"
+)
+
+type TrackerTestSuite struct {
+	suite.Suite
+}
+
+func TestTrackerTestSuite(t *testing.T) {
+	suite.Run(t, new(TrackerTestSuite))
+}
+
+func (ts *TrackerTestSuite) request(remoteAddr string, headers map[string]string) *http.Request {
+	r := httptest.NewRequest("GET", "http://shop.example.com/products/1?utm_source=line&utm_medium=chat&utm_campaign=sale&utm_term=shoe&utm_content=banner", nil)
+	r.RemoteAddr = remoteAddr
+	for key, value := range headers {
+		r.Header.Set(key, value)
+	}
+	return r
+}
+
+func (ts *TrackerTestSuite) TestNewTrackerFromRequest_GivenRequest_ExpectTrackerFilled() {
+	is := assert.New(ts.T())
+	r := ts.request("203.0.113.7:51234", map[string]string{"User-Agent": "test-agent"})
+	r.AddCookie(&http.Cookie{Name: ContactIDCookie, Value: "contact_123"})
+
+	tracker, contactID := NewTrackerFromRequest(r, &TrackerOptions{Event: "pageview", PageTitle: "Shoe"})
+	is.Equal("contact_123", contactID)
+	is.Equal("pageview", tracker.Event)
+	is.Equal("Shoe", tracker.PageTitle)
+	is.Equal("http://shop.example.com/products/1?utm_source=line&utm_medium=chat&utm_campaign=sale&utm_term=shoe&utm_content=banner", tracker.PageURL)
+	is.Equal("utm_source=line&utm_medium=chat&utm_campaign=sale&utm_term=shoe&utm_content=banner", tracker.QueryString)
+	is.Equal("test-agent", tracker.UserAgent)
+	is.Equal("line", tracker.UTMSource)
+	is.Equal("chat", tracker.UTMMedium)
+	is.Equal("sale", tracker.UTMCampaign)
+	is.Equal("shoe", tracker.UTMTerm)
+	is.Equal("banner", tracker.UTMContent)
+	is.Equal("203.0.113.7", tracker.IPAddress)
+}
+
+func (ts *TrackerTestSuite) TestNewTrackerFromRequest_GivenUseReferer_ExpectPageFromReferer() {
+	is := assert.New(ts.T())
+	r := ts.request("203.0.113.7:51234", map[string]string{"Referer": "https://www.example.com/landing?utm_source=email"})
+
+	tracker, contactID := NewTrackerFromRequest(r, &TrackerOptions{UseReferer: true})
+	is.Empty(contactID)
+	is.Equal("https://www.example.com/landing?utm_source=email", tracker.PageURL)
+	is.Equal("email", tracker.UTMSource)
+	is.Empty(tracker.UTMCampaign)
+}
+
+func (ts *TrackerTestSuite) TestNewTrackerFromRequest_GivenProxyRules_ExpectClientIPFromTrustedHopsOnly() {
+	is := assert.New(ts.T())
+	trusted := []string{"10.0.0.0/8", "192.0.2.1", "not-an-ip"}
+	cases := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		expected   string
+	}{
+		{"untrusted peer ignore forwarded headers", "198.51.100.9:1000",
+			map[string]string{"X-Forwarded-For": "1.1.1.1", "X-Real-IP": "2.2.2.2"}, "198.51.100.9"},
+		{"trusted peer use forwarded client", "10.0.0.1:1000",
+			map[string]string{"X-Forwarded-For": "203.0.113.7"}, "203.0.113.7"},
+		{"spoofed leftmost hop is skipped", "10.0.0.1:1000",
+			map[string]string{"X-Forwarded-For": "1.1.1.1, 203.0.113.7, 10.1.1.1"}, "203.0.113.7"},
+		{"single trusted IP entry", "192.0.2.1:1000",
+			map[string]string{"X-Forwarded-For": "203.0.113.7, 192.0.2.1"}, "203.0.113.7"},
+		{"every hop trusted use leftmost", "10.0.0.1:1000",
+			map[string]string{"X-Forwarded-For": "10.2.2.2, 10.3.3.3"}, "10.2.2.2"},
+		{"garbage hop stop walking", "10.0.0.1:1000",
+			map[string]string{"X-Forwarded-For": "203.0.113.7, garbage, 10.1.1.1"}, "10.1.1.1"},
+		{"trusted peer use X-Real-IP", "10.0.0.1:1000",
+			map[string]string{"X-Real-IP": "203.0.113.7"}, "203.0.113.7"},
+		{"trusted peer without headers", "10.0.0.1:1000", nil, "10.0.0.1"},
+	}
+	for _, c := range cases {
+		tracker, _ := NewTrackerFromRequest(ts.request(c.remoteAddr, c.headers), &TrackerOptions{TrustedProxies: trusted})
+		is.Equal(c.expected, tracker.IPAddress, c.name)
+	}
+}
+
+func (ts *TrackerTestSuite) TestNewTrackerFromRequest_GivenForwardedProto_ExpectSchemeFromTrustedPeerOnly() {
+	is := assert.New(ts.T())
+	headers := map[string]string{"X-Forwarded-Proto": "https"}
+	opts := &TrackerOptions{TrustedProxies: []string{"10.0.0.0/8"}}
+
+	tracker, _ := NewTrackerFromRequest(ts.request("10.0.0.1:1000", headers), opts)
+	is.Contains(tracker.PageURL, "https://shop.example.com/products/1?")
+
+	tracker, _ = NewTrackerFromRequest(ts.request("198.51.100.9:1000", headers), opts)
+	is.Contains(tracker.PageURL, "http://shop.example.com/products/1?")
+}